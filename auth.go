@@ -0,0 +1,152 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const authorizationHeaderKey = "Authorization"
+
+// DefaultTokenRefreshLeeway is the time before its expiry a token is refreshed, see `NewRefreshingTokenSource`.
+const DefaultTokenRefreshLeeway = 30 * time.Second
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+// Token is an access token used for the bearer authentication, see `WithBearerToken`.
+type Token struct {
+	// AccessToken is sent as the value of the "Authorization: Bearer" header.
+	AccessToken string
+	// Expiry is the expiration time of the token, zero means that the token never expires.
+	Expiry time.Time
+}
+
+// expired reports whether the token expires in less than "leeway".
+func (t Token) expired(leeway time.Duration) bool {
+	if t.Expiry.IsZero() {
+		return false
+	}
+
+	return time.Now().Add(leeway).After(t.Expiry)
+}
+
+// TokenSource provides the tokens for the bearer authentication, see `WithBearerToken`.
+//
+// If a `TokenSource` implements the `Invalidate()` method too, it is called when the registry
+// responds with 401 Unauthorized and the request is sent once more with a fresh token.
+type TokenSource interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+type tokenInvalidator interface {
+	Invalidate()
+}
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(context.Context) (Token, error) {
+	return Token{AccessToken: string(s)}, nil
+}
+
+// StaticTokenSource returns a `TokenSource` which always returns the same, never expiring, access token.
+func StaticTokenSource(accessToken string) TokenSource {
+	return staticTokenSource(accessToken)
+}
+
+// TokenFunc fetches a new token, e.g. from an OAuth authorization server.
+type TokenFunc func(ctx context.Context) (Token, error)
+
+// RefreshingTokenSource is a `TokenSource` which caches the token of a `TokenFunc`
+// and fetches a new one before the cached one expires or when it's invalidated.
+// It's safe for concurrent use.
+type RefreshingTokenSource struct {
+	fetch  TokenFunc
+	leeway time.Duration
+
+	mu    sync.Mutex
+	token *Token
+}
+
+// NewRefreshingTokenSource returns a `RefreshingTokenSource` which refreshes the token "leeway" before its expiry.
+func NewRefreshingTokenSource(fetch TokenFunc, leeway time.Duration) *RefreshingTokenSource {
+	return &RefreshingTokenSource{fetch: fetch, leeway: leeway}
+}
+
+// Token returns the cached token or fetches a new one if it's about to expire.
+func (s *RefreshingTokenSource) Token(ctx context.Context) (Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && !s.token.expired(s.leeway) {
+		return *s.token, nil
+	}
+
+	t, err := s.fetch(ctx)
+	if err != nil {
+		return Token{}, err
+	}
+	if t.AccessToken == "" {
+		return Token{}, errors.New("client: token source returned an empty access token")
+	}
+
+	s.token = &t
+	return t, nil
+}
+
+// Invalidate drops the cached token, the next `Token` call fetches a new one.
+func (s *RefreshingTokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = nil
+	s.mu.Unlock()
+}
+
+// WithBasicAuth authenticates every request with HTTP Basic authentication,
+// e.g. with the API key and secret of Confluent Cloud.
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.basicAuth = &basicAuth{username: username, password: password}
+		c.tokens = nil
+	}
+}
+
+// WithBearerToken authenticates every request with a bearer token provided by "tokens".
+// Look `StaticTokenSource` and `NewRefreshingTokenSource`.
+func WithBearerToken(tokens TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+		c.basicAuth = nil
+	}
+}
+
+// authenticate sets the authorization header of the request, if any authentication is configured.
+func (c *Client) authenticate(ctx context.Context, req *http.Request) error {
+	if c.basicAuth != nil {
+		req.SetBasicAuth(c.basicAuth.username, c.basicAuth.password)
+		return nil
+	}
+
+	if c.tokens != nil {
+		t, err := c.tokens.Token(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(authorizationHeaderKey, "Bearer "+t.AccessToken)
+	}
+
+	return nil
+}
+
+// invalidateToken invalidates the current token and reports whether a fresh one can be acquired.
+func (c *Client) invalidateToken() bool {
+	inv, ok := c.tokens.(tokenInvalidator)
+	if ok {
+		inv.Invalidate()
+	}
+
+	return ok
+}
@@ -0,0 +1,101 @@
+package schemaregistry
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithBasicAuth(t *testing.T) {
+	c := httpSuccess(t, http.MethodGet, "/subjects", nil, []string{})
+	next := c.client
+	c.client = D(func(req *http.Request) (*http.Response, error) {
+		user, pass, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "key", user)
+		assert.Equal(t, "secret", pass)
+		return next.Do(req)
+	})
+	WithBasicAuth("key", "secret")(c)
+
+	_, err := c.Subjects()
+	assert.NoError(t, err)
+}
+
+func TestWithBearerToken_Static(t *testing.T) {
+	c := httpSuccess(t, http.MethodGet, "/subjects", nil, []string{})
+	next := c.client
+	c.client = D(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "Bearer token", req.Header.Get(authorizationHeaderKey))
+		return next.Do(req)
+	})
+	WithBearerToken(StaticTokenSource("token"))(c)
+
+	_, err := c.Subjects()
+	assert.NoError(t, err)
+}
+
+func TestWithBearerToken_RefreshedOnUnauthorized(t *testing.T) {
+	var fetches int
+	tokens := NewRefreshingTokenSource(func(ctx context.Context) (Token, error) {
+		fetches++
+		return Token{AccessToken: "token-" + string(rune('0'+fetches))}, nil
+	}, 0)
+
+	var seen []string
+	c := httpSuccess(t, http.MethodGet, "/subjects", nil, []string{"subject"})
+	ok := c.client
+	unauthorized := httpError(t, http.StatusUnauthorized, http.StatusUnauthorized, "token revoked").client
+	c.client = D(func(req *http.Request) (*http.Response, error) {
+		token := req.Header.Get(authorizationHeaderKey)
+		seen = append(seen, token)
+		if token == "Bearer token-1" {
+			return unauthorized.Do(req)
+		}
+		return ok.Do(req)
+	})
+	WithBearerToken(tokens)(c)
+
+	subs, err := c.Subjects()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"subject"}, subs)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, seen)
+	assert.Equal(t, 2, fetches)
+}
+
+func TestRefreshingTokenSource(t *testing.T) {
+	var fetches int
+	expiry := time.Now().Add(time.Minute)
+	tokens := NewRefreshingTokenSource(func(ctx context.Context) (Token, error) {
+		fetches++
+		return Token{AccessToken: "token", Expiry: expiry}, nil
+	}, 10*time.Second)
+
+	for i := 0; i < 3; i++ {
+		tok, err := tokens.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "token", tok.AccessToken)
+	}
+	assert.Equal(t, 1, fetches)
+
+	// about to expire, within the leeway.
+	expiry = time.Now().Add(5 * time.Second)
+	tokens.Invalidate()
+	_, err := tokens.Token(context.Background())
+	assert.NoError(t, err)
+	_, err = tokens.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, fetches)
+}
+
+func TestRefreshingTokenSource_FailedEmptyToken(t *testing.T) {
+	tokens := NewRefreshingTokenSource(func(ctx context.Context) (Token, error) {
+		return Token{}, nil
+	}, DefaultTokenRefreshLeeway)
+
+	_, err := tokens.Token(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,422 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+var errShortBuffer = errors.New("avro: unexpected end of data")
+
+// Marshal encodes the value in the Avro binary format of the schema.
+//
+// A union value is encoded with the first branch which accepts it, a value wrapped
+// in a single key map whose key is the name of a branch, e.g. {"long": 1}, selects the branch.
+// A missing field of a record is encoded with its default value.
+func Marshal(s *Schema, v interface{}) ([]byte, error) {
+	return appendValue(nil, s, v)
+}
+
+// Unmarshal decodes data written in the Avro binary format of the schema.
+func Unmarshal(s *Schema, data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+	v, err := d.value(s)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("avro: %d bytes left after the value", len(d.data)-d.pos)
+	}
+	return v, nil
+}
+
+func appendLong(b []byte, n int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], n)]...)
+}
+
+func appendValue(b []byte, s *Schema, v interface{}) ([]byte, error) {
+	switch s.Type {
+	case Null:
+		if v != nil {
+			return nil, typeError(s, v)
+		}
+		return b, nil
+	case Boolean:
+		bv, ok := v.(bool)
+		if !ok {
+			return nil, typeError(s, v)
+		}
+		if bv {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case Int:
+		n, ok := toInt64(v)
+		if !ok || int64(int32(n)) != n {
+			return nil, typeError(s, v)
+		}
+		return appendLong(b, n), nil
+	case Long:
+		n, ok := toInt64(v)
+		if !ok {
+			return nil, typeError(s, v)
+		}
+		return appendLong(b, n), nil
+	case Float:
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, typeError(s, v)
+		}
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], math.Float32bits(float32(f)))
+		return append(b, buf[:]...), nil
+	case Double:
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, typeError(s, v)
+		}
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		return append(b, buf[:]...), nil
+	case Bytes, String:
+		var data []byte
+		switch v := v.(type) {
+		case []byte:
+			data = v
+		case string:
+			data = []byte(v)
+		default:
+			return nil, typeError(s, v)
+		}
+		return append(appendLong(b, int64(len(data))), data...), nil
+	case Fixed:
+		data, ok := v.([]byte)
+		if !ok || len(data) != s.Size {
+			return nil, typeError(s, v)
+		}
+		return append(b, data...), nil
+	case Enum:
+		sym, ok := v.(string)
+		if !ok {
+			return nil, typeError(s, v)
+		}
+		for i, symbol := range s.Symbols {
+			if symbol == sym {
+				return appendLong(b, int64(i)), nil
+			}
+		}
+		return nil, fmt.Errorf("avro: %q is not a symbol of %s", sym, s.Name)
+	case Record:
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, typeError(s, v)
+		}
+		for _, f := range s.Fields {
+			fv, ok := fields[f.Name]
+			if !ok {
+				if !f.HasDefault {
+					return nil, fmt.Errorf("avro: field %q of %s is missing", f.Name, s.Name)
+				}
+				fv = f.Default
+			}
+			var err error
+			if b, err = appendValue(b, f.Type, fv); err != nil {
+				return nil, fmt.Errorf("avro: field %q of %s: %v", f.Name, s.Name, unwrap(err))
+			}
+		}
+		return b, nil
+	case Array:
+		rv := reflect.ValueOf(v)
+		if v == nil || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+			return nil, typeError(s, v)
+		}
+		if rv.Len() > 0 {
+			b = appendLong(b, int64(rv.Len()))
+			for i := 0; i < rv.Len(); i++ {
+				var err error
+				if b, err = appendValue(b, s.Items, rv.Index(i).Interface()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(b, 0), nil
+	case Map:
+		rv := reflect.ValueOf(v)
+		if v == nil || rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+			return nil, typeError(s, v)
+		}
+		if rv.Len() > 0 {
+			// sorted keys, so the same map is always encoded the same way.
+			keys := rv.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+			b = appendLong(b, int64(len(keys)))
+			for _, k := range keys {
+				b = append(appendLong(b, int64(len(k.String()))), k.String()...)
+				var err error
+				if b, err = appendValue(b, s.Values, rv.MapIndex(k).Interface()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return append(b, 0), nil
+	case Union:
+		return appendUnion(b, s, v)
+	}
+
+	return nil, fmt.Errorf("avro: unknown type %s", s.Type)
+}
+
+func appendUnion(b []byte, s *Schema, v interface{}) ([]byte, error) {
+	if wrapped, ok := v.(map[string]interface{}); ok && len(wrapped) == 1 {
+		for i, branch := range s.Branches {
+			if bv, ok := wrapped[branch.String()]; ok {
+				return appendValue(appendLong(b, int64(i)), branch, bv)
+			}
+		}
+	}
+
+	for i, branch := range s.Branches {
+		if encoded, err := appendValue(appendLong(b, int64(i)), branch, v); err == nil {
+			return encoded, nil
+		}
+	}
+	return nil, typeError(s, v)
+}
+
+func typeError(s *Schema, v interface{}) error {
+	return fmt.Errorf("avro: %T(%v) can't be encoded as %s", v, v, s)
+}
+
+// unwrap drops the package prefix of a nested error.
+func unwrap(err error) string {
+	const prefix = "avro: "
+	msg := err.Error()
+	if len(msg) > len(prefix) && msg[:len(prefix)] == prefix {
+		return msg[len(prefix):]
+	}
+	return msg
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint:
+		if uint64(n) > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	case uint64:
+		if n > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		if i, ok := toInt64(v); ok {
+			return float64(i), true
+		}
+		return 0, false
+	}
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) long() (int64, error) {
+	n, size := binary.Varint(d.data[d.pos:])
+	if size <= 0 {
+		return 0, errShortBuffer
+	}
+	d.pos += size
+	return n, nil
+}
+
+func (d *decoder) next(n int64) ([]byte, error) {
+	if n < 0 || n > int64(len(d.data)-d.pos) {
+		return nil, errShortBuffer
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// blockCount reads the count of items of the next block of an array or a map,
+// a negative count is followed by the size of the block in bytes.
+func (d *decoder) blockCount() (int64, error) {
+	n, err := d.long()
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		n = -n
+		if _, err := d.long(); err != nil {
+			return 0, err
+		}
+	}
+	// every item takes at least one byte, except the null ones.
+	if n > int64(len(d.data)-d.pos) && n > 1<<20 {
+		return 0, errShortBuffer
+	}
+	return n, nil
+}
+
+func (d *decoder) value(s *Schema) (interface{}, error) {
+	switch s.Type {
+	case Null:
+		return nil, nil
+	case Boolean:
+		b, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case Int:
+		n, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if int64(int32(n)) != n {
+			return nil, fmt.Errorf("avro: %d overflows int", n)
+		}
+		return int32(n), nil
+	case Long:
+		return d.long()
+	case Float:
+		b, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+	case Double:
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case Bytes, String:
+		n, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		if s.Type == String {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+	case Fixed:
+		b, err := d.next(int64(s.Size))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case Enum:
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(s.Symbols)) {
+			return nil, fmt.Errorf("avro: invalid symbol index %d of %s", i, s.Name)
+		}
+		return s.Symbols[i], nil
+	case Record:
+		fields := make(map[string]interface{}, len(s.Fields))
+		for _, f := range s.Fields {
+			v, err := d.value(f.Type)
+			if err != nil {
+				return nil, err
+			}
+			fields[f.Name] = v
+		}
+		return fields, nil
+	case Array:
+		items := []interface{}{}
+		for {
+			n, err := d.blockCount()
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				return items, nil
+			}
+			for ; n > 0; n-- {
+				v, err := d.value(s.Items)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, v)
+			}
+		}
+	case Map:
+		values := map[string]interface{}{}
+		for {
+			n, err := d.blockCount()
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				return values, nil
+			}
+			for ; n > 0; n-- {
+				size, err := d.long()
+				if err != nil {
+					return nil, err
+				}
+				k, err := d.next(size)
+				if err != nil {
+					return nil, err
+				}
+				v, err := d.value(s.Values)
+				if err != nil {
+					return nil, err
+				}
+				values[string(k)] = v
+			}
+		}
+	case Union:
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(s.Branches)) {
+			return nil, fmt.Errorf("avro: invalid union branch %d", i)
+		}
+		return d.value(s.Branches[i])
+	}
+
+	return nil, fmt.Errorf("avro: unknown type %s", s.Type)
+}
@@ -0,0 +1,122 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const userSchema = `{
+  "type": "record",
+  "name": "User",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "name", "type": "string"},
+    {"name": "age", "type": "int", "default": 18},
+    {"name": "email", "type": ["null", "string"], "default": null},
+    {"name": "role", "type": {"type": "enum", "name": "Role", "symbols": ["ADMIN", "USER"]}, "default": "USER"},
+    {"name": "tags", "type": {"type": "array", "items": "string"}, "default": []},
+    {"name": "scores", "type": {"type": "map", "values": "double"}, "default": {}},
+    {"name": "id", "type": {"type": "fixed", "name": "ID", "size": 4}, "default": "\u0000\u0000\u0000\u0001"},
+    {"name": "friend", "type": ["null", "User"], "default": null}
+  ]
+}`
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	s, err := Parse(userSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := map[string]interface{}{
+		"name":   "bob",
+		"age":    int32(42),
+		"email":  "bob@example.com",
+		"role":   "ADMIN",
+		"tags":   []interface{}{"a", "b"},
+		"scores": map[string]interface{}{"x": 1.5},
+		"id":     []byte{1, 2, 3, 4},
+		"friend": map[string]interface{}{"name": "alice"},
+	}
+	data, err := Marshal(s, in)
+	assert.NoError(t, err)
+
+	out, err := Unmarshal(s, data)
+	assert.NoError(t, err)
+
+	// the friend got the defaults of the missing fields.
+	friend := out.(map[string]interface{})["friend"].(map[string]interface{})
+	assert.Equal(t, int32(18), friend["age"])
+	assert.Equal(t, "USER", friend["role"])
+	assert.Equal(t, []byte{0, 0, 0, 1}, friend["id"])
+	assert.Nil(t, friend["friend"])
+
+	delete(out.(map[string]interface{}), "friend")
+	delete(in, "friend")
+	assert.Equal(t, in, out)
+}
+
+func TestMarshal_Primitives(t *testing.T) {
+	tests := []struct {
+		schema string
+		in     interface{}
+		data   []byte
+		out    interface{}
+	}{
+		{`"null"`, nil, nil, nil},
+		{`"boolean"`, true, []byte{1}, true},
+		{`"int"`, 1, []byte{2}, int32(1)},
+		{`"int"`, -1, []byte{1}, int32(-1)},
+		{`"long"`, int64(64), []byte{0x80, 0x01}, int64(64)},
+		{`"float"`, float32(1), []byte{0, 0, 0x80, 0x3f}, float32(1)},
+		{`"double"`, 2, []byte{0, 0, 0, 0, 0, 0, 0, 0x40}, float64(2)},
+		{`"string"`, "foo", []byte{6, 'f', 'o', 'o'}, "foo"},
+		{`"bytes"`, []byte{0xff}, []byte{2, 0xff}, []byte{0xff}},
+		{`["null", "long"]`, int64(1), []byte{2, 2}, int64(1)},
+		{`["null", "long"]`, nil, []byte{0}, nil},
+		{`["int", "long"]`, map[string]interface{}{"long": 1}, []byte{2, 2}, int64(1)},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.schema)
+		if !assert.NoError(t, err, tt.schema) {
+			continue
+		}
+		data, err := Marshal(s, tt.in)
+		assert.NoError(t, err, tt.schema)
+		assert.Equal(t, tt.data, data, tt.schema)
+
+		out, err := Unmarshal(s, data)
+		assert.NoError(t, err, tt.schema)
+		assert.Equal(t, tt.out, out, tt.schema)
+	}
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	s, err := Parse(userSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Marshal(s, map[string]interface{}{"age": 1})
+	assert.EqualError(t, err, `avro: field "name" of com.example.User is missing`)
+
+	_, err = Marshal(s, map[string]interface{}{"name": "bob", "role": "GUEST"})
+	assert.Error(t, err)
+
+	_, err = Marshal(s, map[string]interface{}{"name": "bob", "age": int64(1) << 40})
+	assert.Error(t, err)
+}
+
+func TestUnmarshal_Truncated(t *testing.T) {
+	s, err := Parse(`"string"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Unmarshal(s, []byte{6, 'f'})
+	assert.Error(t, err)
+
+	_, err = Unmarshal(s, []byte{2, 'f', 'x'})
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package avro
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// CanonicalForm returns the Parsing Canonical Form of the schema: the attributes irrelevant to reading data,
+// e.g. doc, aliases and defaults, are dropped, the names are full names and there is no whitespace.
+// Two schemas with the same canonical form read and write the same data.
+func (s *Schema) CanonicalForm() string {
+	var b strings.Builder
+	writeCanonical(&b, s, make(map[string]bool))
+	return b.String()
+}
+
+func writeCanonical(b *strings.Builder, s *Schema, defined map[string]bool) {
+	if s.IsNamed() {
+		// a named type is written once, the next occurrences are its name.
+		if defined[s.Name] {
+			writeString(b, s.Name)
+			return
+		}
+		defined[s.Name] = true
+	}
+
+	switch s.Type {
+	case Record:
+		b.WriteString(`{"name":`)
+		writeString(b, s.Name)
+		b.WriteString(`,"type":"record","fields":[`)
+		for i, f := range s.Fields {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(`{"name":`)
+			writeString(b, f.Name)
+			b.WriteString(`,"type":`)
+			writeCanonical(b, f.Type, defined)
+			b.WriteByte('}')
+		}
+		b.WriteString("]}")
+	case Enum:
+		b.WriteString(`{"name":`)
+		writeString(b, s.Name)
+		b.WriteString(`,"type":"enum","symbols":[`)
+		for i, sym := range s.Symbols {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeString(b, sym)
+		}
+		b.WriteString("]}")
+	case Fixed:
+		b.WriteString(`{"name":`)
+		writeString(b, s.Name)
+		b.WriteString(`,"type":"fixed","size":`)
+		b.WriteString(strconv.Itoa(s.Size))
+		b.WriteByte('}')
+	case Array:
+		b.WriteString(`{"type":"array","items":`)
+		writeCanonical(b, s.Items, defined)
+		b.WriteByte('}')
+	case Map:
+		b.WriteString(`{"type":"map","values":`)
+		writeCanonical(b, s.Values, defined)
+		b.WriteByte('}')
+	case Union:
+		b.WriteByte('[')
+		for i, branch := range s.Branches {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonical(b, branch, defined)
+		}
+		b.WriteByte(']')
+	default:
+		writeString(b, string(s.Type))
+	}
+}
+
+func writeString(b *strings.Builder, s string) {
+	// the names and symbols are validated, they never need to be escaped.
+	quoted, _ := json.Marshal(s)
+	b.Write(quoted)
+}
+
+// crc64Empty is the fingerprint of the empty string and the polynomial of CRC-64-AVRO.
+const crc64Empty uint64 = 0xc15d213aa4d7a795
+
+var crc64Table = func() (table [256]uint64) {
+	for i := range table {
+		fp := uint64(i)
+		for j := 0; j < 8; j++ {
+			fp = (fp >> 1) ^ (crc64Empty & -(fp & 1))
+		}
+		table[i] = fp
+	}
+	return table
+}()
+
+// FingerprintCRC64 returns the CRC-64-AVRO fingerprint of the canonical form, the one of the
+// single object encoding, which writes it in little-endian order.
+func (s *Schema) FingerprintCRC64() uint64 {
+	fp := crc64Empty
+	for _, c := range []byte(s.CanonicalForm()) {
+		fp = (fp >> 8) ^ crc64Table[byte(fp)^c]
+	}
+	return fp
+}
+
+// FingerprintMD5 returns the MD5 fingerprint of the canonical form.
+func (s *Schema) FingerprintMD5() [md5.Size]byte {
+	return md5.Sum([]byte(s.CanonicalForm()))
+}
+
+// FingerprintSHA256 returns the SHA-256 fingerprint of the canonical form.
+func (s *Schema) FingerprintSHA256() [sha256.Size]byte {
+	return sha256.Sum256([]byte(s.CanonicalForm()))
+}
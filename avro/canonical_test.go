@@ -0,0 +1,43 @@
+package avro
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalForm(t *testing.T) {
+	tests := []struct {
+		schema, canonical string
+	}{
+		{`{"type": "int"}`, `"int"`},
+		{`{"type": "fixed", "size": 16, "name": "md5", "namespace": "org.example", "doc": "checksum"}`, `{"name":"org.example.md5","type":"fixed","size":16}`},
+		{
+			`{"fields": [{"type": {"items": "Node", "type": "array"}, "name": "children", "default": []}], "name": "Node", "type": "record", "aliases": ["Tree"]}`,
+			`{"name":"Node","type":"record","fields":[{"name":"children","type":{"type":"array","items":"Node"}}]}`,
+		},
+		{`["null", {"type": "enum", "name": "E", "symbols": ["A", "B"], "default": "A"}]`, `["null",{"name":"E","type":"enum","symbols":["A","B"]}]`},
+		{`{"type": "map", "values": {"type": "long", "logicalType": "timestamp-millis"}}`, `{"type":"map","values":"long"}`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.canonical, mustParse(t, tt.schema).CanonicalForm())
+	}
+}
+
+func TestFingerprints(t *testing.T) {
+	// the values of the test suite of the specification.
+	tests := map[string]int64{
+		`"null"`:    7195948357588979594,
+		`"boolean"`: -6970731678124411036,
+		`"int"`:     8247732601305521295,
+		`{"type":"fixed","name":"Foo","size":15}`: 3524383828543974029,
+	}
+	for schema, fp := range tests {
+		assert.Equal(t, fp, int64(mustParse(t, schema).FingerprintCRC64()), schema)
+	}
+
+	s := mustParse(t, `"int"`)
+	assert.Equal(t, "ef524ea1b91e73173d938ade36c1db32", fmt.Sprintf("%x", s.FingerprintMD5()))
+	assert.Equal(t, "3f2b87a9fe7cc9b13835598c3981cd45e3e355309e5090aa0933d7becb6fba45", fmt.Sprintf("%x", s.FingerprintSHA256()))
+}
@@ -0,0 +1,253 @@
+package avro
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Incompatibility is a reason why a reader schema can't read the data written with a writer schema.
+type Incompatibility struct {
+	// Path locates the incompatible part of the reader schema by field names, e.g. "/address/city",
+	// "items" and "values" stand for the items of an array and the values of a map, "/" is the root.
+	Path    string
+	Message string
+}
+
+func (i Incompatibility) String() string {
+	return i.Path + ": " + i.Message
+}
+
+// CanRead checks whether the data written with the writer schema can be read with the reader schema,
+// following the schema resolution rules of the Avro specification. It returns every incompatibility
+// found, none when the reader can read all the data of the writer. Like the registry, it compares
+// the underlying types only, the logical types are ignored, look `CanReadStrict`.
+func CanRead(reader, writer *Schema) []Incompatibility {
+	c := &checker{seen: make(map[[2]*Schema]bool)}
+	c.check(reader, writer, "")
+	return c.incompatibilities
+}
+
+// CanReadStrict same as `CanRead` but it checks the logical types too: a value of a logical type can't
+// be read as another logical type, e.g. timestamp-millis as timestamp-micros, and a decimal keeps its
+// scale and doesn't lose precision. The registry accepts these changes, which alter the meaning of the data.
+func CanReadStrict(reader, writer *Schema) []Incompatibility {
+	c := &checker{seen: make(map[[2]*Schema]bool), strict: true}
+	c.check(reader, writer, "")
+	return c.incompatibilities
+}
+
+type checker struct {
+	// seen are the pairs of named types checked, or being checked, they stop the recursive types.
+	seen map[[2]*Schema]bool
+	// strict tells whether the logical types are checked.
+	strict            bool
+	incompatibilities []Incompatibility
+}
+
+func (c *checker) report(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "/"
+	}
+	c.incompatibilities = append(c.incompatibilities, Incompatibility{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// try reports whether the reader can read the writer without reporting anything.
+func (c *checker) try(reader, writer *Schema, path string) bool {
+	trial := &checker{seen: make(map[[2]*Schema]bool, len(c.seen)), strict: c.strict}
+	for pair := range c.seen {
+		trial.seen[pair] = true
+	}
+	trial.check(reader, writer, path)
+	return len(trial.incompatibilities) == 0
+}
+
+func (c *checker) check(reader, writer *Schema, path string) {
+	if reader.IsNamed() && writer.IsNamed() {
+		pair := [2]*Schema{reader, writer}
+		if c.seen[pair] {
+			return
+		}
+		c.seen[pair] = true
+	}
+
+	// every branch the writer may have written must be readable.
+	if writer.Type == Union {
+		for _, branch := range writer.Branches {
+			c.check(reader, branch, path)
+		}
+		return
+	}
+	if reader.Type == Union {
+		c.checkUnion(reader, writer, path)
+		return
+	}
+
+	if reader.Type != writer.Type {
+		if !promotable(writer.Type, reader.Type) {
+			c.report(path, "%s can't be read as %s", describe(writer), describe(reader))
+		}
+		return
+	}
+
+	switch reader.Type {
+	case Record:
+		c.checkRecord(reader, writer, path)
+	case Enum:
+		c.checkEnum(reader, writer, path)
+	case Fixed:
+		if !namesMatch(reader, writer) {
+			c.report(path, "fixed %s can't be read as fixed %s", writer.Name, reader.Name)
+		} else if reader.Size != writer.Size {
+			c.report(path, "the size of fixed %s changed from %d to %d", reader.Name, writer.Size, reader.Size)
+		}
+	case Array:
+		c.check(reader.Items, writer.Items, path+"/items")
+	case Map:
+		c.check(reader.Values, writer.Values, path+"/values")
+	}
+	if c.strict {
+		c.checkLogicalType(reader, writer, path)
+	}
+}
+
+// checkUnion checks a reader union against a writer which is not a union: the reader uses the first
+// branch of the same type, or else the first branch the writer can be promoted to.
+func (c *checker) checkUnion(reader, writer *Schema, path string) {
+	for _, branch := range reader.Branches {
+		if branch.Type == writer.Type && (!branch.IsNamed() || namesMatch(branch, writer)) {
+			c.check(branch, writer, path)
+			return
+		}
+	}
+	for _, branch := range reader.Branches {
+		if c.try(branch, writer, path) {
+			return
+		}
+	}
+	c.report(path, "%s can't be read by any branch of %s", describe(writer), describe(reader))
+}
+
+func (c *checker) checkRecord(reader, writer *Schema, path string) {
+	if !namesMatch(reader, writer) {
+		c.report(path, "record %s can't be read as record %s", writer.Name, reader.Name)
+		return
+	}
+
+	// the writer fields the reader doesn't have are skipped, the reader fields the writer
+	// doesn't have take their default.
+	for _, rf := range reader.Fields {
+		fieldPath := path + "/" + rf.Name
+		wf := writerField(writer, rf)
+		if wf == nil {
+			if !rf.HasDefault {
+				c.report(fieldPath, "field %q of record %s has no default and the writer doesn't have it", rf.Name, reader.Name)
+			}
+			continue
+		}
+		c.check(rf.Type, wf.Type, fieldPath)
+	}
+}
+
+func (c *checker) checkEnum(reader, writer *Schema, path string) {
+	if !namesMatch(reader, writer) {
+		c.report(path, "enum %s can't be read as enum %s", writer.Name, reader.Name)
+		return
+	}
+	// unknown symbols are read as the default of the enum.
+	if reader.EnumDefault != "" {
+		return
+	}
+
+	var missing []string
+	for _, sym := range writer.Symbols {
+		if !contains(reader.Symbols, sym) {
+			missing = append(missing, sym)
+		}
+	}
+	if len(missing) > 0 {
+		c.report(path, "enum %s has no default and misses the symbols %s", reader.Name, strings.Join(missing, ", "))
+	}
+}
+
+// checkLogicalType checks the annotations of types which are compatible otherwise, a logical type
+// only on one side is fine: the reader which doesn't know it reads the underlying type.
+func (c *checker) checkLogicalType(reader, writer *Schema, path string) {
+	if reader.LogicalType == "" || writer.LogicalType == "" {
+		return
+	}
+	if reader.LogicalType != writer.LogicalType {
+		c.report(path, "%s can't be read as %s", writer.LogicalType, reader.LogicalType)
+		return
+	}
+	if reader.LogicalType == "decimal" {
+		if reader.Scale != writer.Scale {
+			c.report(path, "the scale of the decimal changed from %d to %d", writer.Scale, reader.Scale)
+		} else if reader.Precision < writer.Precision {
+			c.report(path, "the precision of the decimal decreased from %d to %d", writer.Precision, reader.Precision)
+		}
+	}
+}
+
+// promotable reports whether a value of the writer type can be read as a value of the reader type.
+func promotable(writer, reader Type) bool {
+	switch writer {
+	case Int:
+		return reader == Long || reader == Float || reader == Double
+	case Long:
+		return reader == Float || reader == Double
+	case Float:
+		return reader == Double
+	case String:
+		return reader == Bytes
+	case Bytes:
+		return reader == String
+	}
+	return false
+}
+
+// namesMatch reports whether the named types have the same unqualified name,
+// or whether the writer name is an alias of the reader.
+func namesMatch(reader, writer *Schema) bool {
+	if unqualified(reader.Name) == unqualified(writer.Name) {
+		return true
+	}
+	return contains(reader.Aliases, writer.Name)
+}
+
+// writerField returns the field of the writer record the reader field reads, by name or by alias.
+func writerField(writer *Schema, rf *Field) *Field {
+	if f := writer.Field(rf.Name); f != nil {
+		return f
+	}
+	for _, alias := range rf.Aliases {
+		if f := writer.Field(alias); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func unqualified(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// describe returns the type of the schema for the messages, unions are described by their branches.
+func describe(s *Schema) string {
+	if s.Type != Union {
+		return s.String()
+	}
+	branches := make([]string, 0, len(s.Branches))
+	for _, b := range s.Branches {
+		branches = append(branches, b.String())
+	}
+	return "[" + strings.Join(branches, ", ") + "]"
+}
+
+func contains(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package avro
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParse(t *testing.T, schema string) *Schema {
+	t.Helper()
+	s, err := Parse(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCanRead(t *testing.T) {
+	tests := []struct {
+		name           string
+		reader, writer string
+		paths          []string
+	}{
+		{"same", userSchema, userSchema, nil},
+		{"promotion", `"long"`, `"int"`, nil},
+		{"no demotion", `"int"`, `"long"`, []string{"/"}},
+		{"string as bytes", `"bytes"`, `"string"`, nil},
+		{
+			"added field with default",
+			`{"type":"record","name":"R","fields":[{"name":"a","type":"int"},{"name":"b","type":"int","default":0}]}`,
+			`{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`,
+			nil,
+		},
+		{
+			"added field without default",
+			`{"type":"record","name":"R","fields":[{"name":"a","type":"int"},{"name":"b","type":"int"}]}`,
+			`{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`,
+			[]string{"/b"},
+		},
+		{
+			"renamed field with alias",
+			`{"type":"record","name":"R","fields":[{"name":"b","type":"int","aliases":["a"]}]}`,
+			`{"type":"record","name":"R","fields":[{"name":"a","type":"int"}]}`,
+			nil,
+		},
+		{
+			"renamed record with alias",
+			`{"type":"record","name":"S","aliases":["R"],"fields":[]}`,
+			`{"type":"record","name":"R","fields":[]}`,
+			nil,
+		},
+		{
+			"renamed record",
+			`{"type":"record","name":"S","fields":[]}`,
+			`{"type":"record","name":"R","fields":[]}`,
+			[]string{"/"},
+		},
+		{
+			"nested field",
+			`{"type":"record","name":"R","fields":[{"name":"a","type":{"type":"array","items":"int"}}]}`,
+			`{"type":"record","name":"R","fields":[{"name":"a","type":{"type":"array","items":"string"}}]}`,
+			[]string{"/a/items"},
+		},
+		{"removed enum symbol", `{"type":"enum","name":"E","symbols":["A"]}`, `{"type":"enum","name":"E","symbols":["A","B"]}`, []string{"/"}},
+		{"enum default", `{"type":"enum","name":"E","symbols":["A"],"default":"A"}`, `{"type":"enum","name":"E","symbols":["A","B"]}`, nil},
+		{"fixed size", `{"type":"fixed","name":"F","size":4}`, `{"type":"fixed","name":"F","size":8}`, []string{"/"}},
+		{"widened union", `["null","string"]`, `"string"`, nil},
+		{"union promotion", `["null","long"]`, `"int"`, nil},
+		{"narrowed union", `"string"`, `["null","string"]`, []string{"/"}},
+		{"union branch", `["null","int"]`, `"string"`, []string{"/"}},
+		// the logical types are ignored, like the registry does.
+		{
+			"decimal scale",
+			`{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}`,
+			`{"type":"bytes","logicalType":"decimal","precision":10,"scale":3}`,
+			nil,
+		},
+		{"logical type", `{"type":"long","logicalType":"timestamp-micros"}`, `{"type":"long","logicalType":"timestamp-millis"}`, nil},
+		{"logical type removed", `"long"`, `{"type":"long","logicalType":"timestamp-millis"}`, nil},
+	}
+
+	for _, tt := range tests {
+		var paths []string
+		for _, inc := range CanRead(mustParse(t, tt.reader), mustParse(t, tt.writer)) {
+			paths = append(paths, inc.Path)
+		}
+		assert.Equal(t, tt.paths, paths, tt.name)
+	}
+}
+
+func TestCanReadStrict(t *testing.T) {
+	tests := []struct {
+		name           string
+		reader, writer string
+		paths          []string
+	}{
+		{
+			"decimal scale",
+			`{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}`,
+			`{"type":"bytes","logicalType":"decimal","precision":10,"scale":3}`,
+			[]string{"/"},
+		},
+		{
+			"decimal precision",
+			`{"type":"bytes","logicalType":"decimal","precision":8,"scale":2}`,
+			`{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}`,
+			[]string{"/"},
+		},
+		{
+			"nested logical type",
+			`{"type":"record","name":"R","fields":[{"name":"at","type":{"type":"long","logicalType":"timestamp-micros"}}]}`,
+			`{"type":"record","name":"R","fields":[{"name":"at","type":{"type":"long","logicalType":"timestamp-millis"}}]}`,
+			[]string{"/at"},
+		},
+		{"logical type removed", `"long"`, `{"type":"long","logicalType":"timestamp-millis"}`, nil},
+		{"underlying type", `{"type":"int","logicalType":"date"}`, `"string"`, []string{"/"}},
+	}
+
+	for _, tt := range tests {
+		var paths []string
+		for _, inc := range CanReadStrict(mustParse(t, tt.reader), mustParse(t, tt.writer)) {
+			paths = append(paths, inc.Path)
+		}
+		assert.Equal(t, tt.paths, paths, tt.name)
+	}
+}
+
+func TestCanRead_Recursive(t *testing.T) {
+	list := `{"type":"record","name":"List","fields":[{"name":"next","type":["null","List"]}%s]}`
+	reader := mustParse(t, fmt.Sprintf(list, `,{"name":"value","type":"long"}`))
+	writer := mustParse(t, fmt.Sprintf(list, `,{"name":"value","type":"int"}`))
+
+	assert.Empty(t, CanRead(reader, writer))
+	incs := CanRead(writer, reader)
+	if assert.Len(t, incs, 1) {
+		assert.Equal(t, "/value: long can't be read as int", incs[0].String())
+	}
+}
@@ -0,0 +1,641 @@
+// Package avro parses Avro schemas and encodes and decodes the Avro binary format,
+// it has no dependencies outside of the standard library.
+//
+// Values are represented by native Go types: nil for null, bool, int32 for int, int64 for long,
+// float32 for float, float64 for double, []byte for bytes and fixed, string for string and enum,
+// map[string]interface{} for records and maps and []interface{} for arrays.
+// A union value is the value of one of its branches.
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Type is the type of a schema.
+type Type string
+
+// The Avro types.
+const (
+	Null    Type = "null"
+	Boolean Type = "boolean"
+	Int     Type = "int"
+	Long    Type = "long"
+	Float   Type = "float"
+	Double  Type = "double"
+	Bytes   Type = "bytes"
+	String  Type = "string"
+	Record  Type = "record"
+	Enum    Type = "enum"
+	Array   Type = "array"
+	Map     Type = "map"
+	Union   Type = "union"
+	Fixed   Type = "fixed"
+)
+
+var primitives = map[Type]bool{
+	Null: true, Boolean: true, Int: true, Long: true, Float: true, Double: true, Bytes: true, String: true,
+}
+
+// Schema is a parsed Avro schema. Named types, records, enums and fixed, are parsed once,
+// every reference to a named type points to the same `Schema`, so recursive types are cyclic.
+type Schema struct {
+	Type Type
+	// Name is the full name of a named type, e.g. "com.example.User".
+	Name string
+	// Aliases are the full names of the aliases of a named type or of a field.
+	Aliases []string
+	Doc     string
+
+	// Fields of a record.
+	Fields []*Field
+	// Symbols of an enum.
+	Symbols []string
+	// EnumDefault is the symbol a reader uses for unknown symbols, empty when not set.
+	EnumDefault string
+	// Items of an array.
+	Items *Schema
+	// Values of a map.
+	Values *Schema
+	// Branches of a union.
+	Branches []*Schema
+	// Size of a fixed.
+	Size int
+
+	// LogicalType annotates the type, e.g. "timestamp-millis" or "decimal".
+	LogicalType string
+	// Precision and Scale of a decimal.
+	Precision int
+	Scale     int
+}
+
+// Field is a field of a record.
+type Field struct {
+	Name    string
+	Aliases []string
+	Doc     string
+	Type    *Schema
+	// Default is the default value of the field, already converted to its native Go value,
+	// it's meaningful only if `HasDefault` is true.
+	Default    interface{}
+	HasDefault bool
+	// Order is the sort order of the field, "ascending", "descending" or "ignore".
+	Order string
+}
+
+// Namespace returns the namespace of a named type.
+func (s *Schema) Namespace() string {
+	if i := strings.LastIndex(s.Name, "."); i >= 0 {
+		return s.Name[:i]
+	}
+	return ""
+}
+
+// IsNamed reports whether the schema is a record, an enum or a fixed.
+func (s *Schema) IsNamed() bool {
+	return s.Type == Record || s.Type == Enum || s.Type == Fixed
+}
+
+// Field returns the field of a record with the given name, nil when there is no such field.
+func (s *Schema) Field(name string) *Field {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// String returns the name of the type, the full name for the named types.
+func (s *Schema) String() string {
+	if s.IsNamed() {
+		return s.Name
+	}
+	return string(s.Type)
+}
+
+// Parse parses an Avro schema. The references are the schemas the schema refers to,
+// dependencies first, the schema can use their named types by name.
+func Parse(schema string, references ...string) (*Schema, error) {
+	p := &parser{names: make(map[string]*Schema)}
+	for _, ref := range references {
+		if _, err := p.parseJSON(ref); err != nil {
+			return nil, fmt.Errorf("avro: reference: %v", err)
+		}
+	}
+
+	s, err := p.parseJSON(schema)
+	if err != nil {
+		return nil, fmt.Errorf("avro: %v", err)
+	}
+	return s, nil
+}
+
+type parser struct {
+	// names are the named types defined so far, by full name.
+	names map[string]*Schema
+}
+
+func (p *parser) parseJSON(schema string) (*Schema, error) {
+	dec := json.NewDecoder(strings.NewReader(schema))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid json: %v", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid json: unexpected data after the schema")
+	}
+
+	return p.parse(v, "")
+}
+
+// parse parses a schema in its decoded JSON form, the namespace is the enclosing one.
+func (p *parser) parse(v interface{}, namespace string) (*Schema, error) {
+	switch v := v.(type) {
+	case string:
+		return p.parseName(v, namespace)
+	case []interface{}:
+		return p.parseUnion(v, namespace)
+	case map[string]interface{}:
+		return p.parseObject(v, namespace)
+	default:
+		return nil, fmt.Errorf("unexpected schema %v", v)
+	}
+}
+
+// parseName parses a primitive type or a reference to a named type.
+func (p *parser) parseName(name, namespace string) (*Schema, error) {
+	if primitives[Type(name)] {
+		return &Schema{Type: Type(name)}, nil
+	}
+
+	if s, ok := p.names[fullName(name, namespace)]; ok {
+		return s, nil
+	}
+	// a name without namespace may refer to a type of the null namespace.
+	if s, ok := p.names[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", name)
+}
+
+func (p *parser) parseUnion(branches []interface{}, namespace string) (*Schema, error) {
+	s := &Schema{Type: Union}
+	seen := make(map[string]bool)
+	for _, b := range branches {
+		branch, err := p.parse(b, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if branch.Type == Union {
+			return nil, fmt.Errorf("a union can't contain a union")
+		}
+		key := branch.String()
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate %q in union", key)
+		}
+		seen[key] = true
+		s.Branches = append(s.Branches, branch)
+	}
+	return s, nil
+}
+
+func (p *parser) parseObject(obj map[string]interface{}, namespace string) (*Schema, error) {
+	t, ok := obj["type"]
+	if !ok {
+		return nil, fmt.Errorf("missing type in %v", obj)
+	}
+
+	typ, ok := t.(string)
+	if !ok {
+		// e.g. {"type": {"type": "string"}}
+		return p.parse(t, namespace)
+	}
+
+	var (
+		s   *Schema
+		err error
+	)
+	switch Type(typ) {
+	case Record, "error":
+		s, err = p.parseRecord(obj, namespace)
+	case Enum:
+		s, err = p.parseEnum(obj, namespace)
+	case Fixed:
+		s, err = p.parseFixed(obj, namespace)
+	case Array:
+		items, ok := obj["items"]
+		if !ok {
+			return nil, fmt.Errorf("array without items")
+		}
+		s = &Schema{Type: Array}
+		s.Items, err = p.parse(items, namespace)
+	case Map:
+		values, ok := obj["values"]
+		if !ok {
+			return nil, fmt.Errorf("map without values")
+		}
+		s = &Schema{Type: Map}
+		s.Values, err = p.parse(values, namespace)
+	default:
+		if !primitives[Type(typ)] {
+			// a reference to a named type, in the object form.
+			return p.parseName(typ, namespace)
+		}
+		s = &Schema{Type: Type(typ)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lt, ok := obj["logicalType"].(string); ok {
+		s.LogicalType = lt
+		s.Precision, _ = intProp(obj, "precision")
+		s.Scale, _ = intProp(obj, "scale")
+		// as the specification says, an invalid logical type is ignored and the underlying type is used.
+		if err := validateLogicalType(s); err != nil {
+			s.LogicalType, s.Precision, s.Scale = "", 0, 0
+		}
+	}
+	return s, nil
+}
+
+// validateLogicalType checks the underlying type and the attributes of the logical types
+// of the specification, the other logical types are kept but not checked.
+// It returns why the logical type is invalid.
+func validateLogicalType(s *Schema) error {
+	var want Type
+	switch s.LogicalType {
+	case "decimal":
+		if s.Type != Bytes && s.Type != Fixed {
+			return fmt.Errorf("decimal must be bytes or fixed, not %s", s.Type)
+		}
+		if s.Precision <= 0 {
+			return fmt.Errorf("decimal without a positive precision")
+		}
+		if s.Scale < 0 || s.Scale > s.Precision {
+			return fmt.Errorf("decimal scale %d must be between 0 and the precision %d", s.Scale, s.Precision)
+		}
+		// the digits a signed number of size bytes can hold.
+		if max := int(math.Floor(math.Log10(2) * float64(8*s.Size-1))); s.Type == Fixed && s.Precision > max {
+			return fmt.Errorf("decimal precision %d doesn't fit in fixed %s of %d bytes", s.Precision, s.Name, s.Size)
+		}
+		return nil
+	case "duration":
+		if s.Type != Fixed || s.Size != 12 {
+			return fmt.Errorf("duration must be a fixed of 12 bytes")
+		}
+		return nil
+	case "uuid":
+		want = String
+	case "date", "time-millis":
+		want = Int
+	case "time-micros", "timestamp-millis", "timestamp-micros", "local-timestamp-millis", "local-timestamp-micros":
+		want = Long
+	default:
+		return nil
+	}
+
+	if s.Type != want {
+		return fmt.Errorf("%s must be %s, not %s", s.LogicalType, want, s.Type)
+	}
+	return nil
+}
+
+// parseNamed parses the name, namespace, aliases and doc of a named type and defines it.
+func (p *parser) parseNamed(s *Schema, obj map[string]interface{}, namespace string) (string, error) {
+	name, ok := obj["name"].(string)
+	if !ok || name == "" {
+		return "", fmt.Errorf("%s without name", s.Type)
+	}
+	if ns, ok := obj["namespace"].(string); ok {
+		namespace = ns
+	}
+	if err := validateFullName(name); err != nil {
+		return "", err
+	}
+	if err := validateFullName(namespace); namespace != "" && err != nil {
+		return "", fmt.Errorf("invalid namespace %q", namespace)
+	}
+
+	s.Name = fullName(name, namespace)
+	s.Doc, _ = obj["doc"].(string)
+	for _, a := range stringsProp(obj, "aliases") {
+		if err := validateFullName(a); err != nil {
+			return "", err
+		}
+		s.Aliases = append(s.Aliases, fullName(a, s.Namespace()))
+	}
+
+	if primitives[Type(s.Name)] {
+		return "", fmt.Errorf("%q is a primitive type, it can't be redefined", s.Name)
+	}
+	if _, ok := p.names[s.Name]; ok {
+		return "", fmt.Errorf("%q is already defined", s.Name)
+	}
+	p.names[s.Name] = s
+
+	// the namespace of the type is the enclosing namespace of its children.
+	return s.Namespace(), nil
+}
+
+func (p *parser) parseRecord(obj map[string]interface{}, namespace string) (*Schema, error) {
+	s := &Schema{Type: Record}
+	namespace, err := p.parseNamed(s, obj, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, ok := obj["fields"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("record %q without fields", s.Name)
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		fobj, ok := f.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("record %q: invalid field %v", s.Name, f)
+		}
+		field, err := p.parseField(fobj, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("record %q: %v", s.Name, err)
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("record %q: duplicate field %q", s.Name, field.Name)
+		}
+		seen[field.Name] = true
+		s.Fields = append(s.Fields, field)
+	}
+	return s, nil
+}
+
+func (p *parser) parseField(obj map[string]interface{}, namespace string) (*Field, error) {
+	name, ok := obj["name"].(string)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("field without name")
+	}
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid field name %q", name)
+	}
+
+	t, ok := obj["type"]
+	if !ok {
+		return nil, fmt.Errorf("field %q without type", name)
+	}
+	typ, err := p.parse(t, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("field %q: %v", name, err)
+	}
+
+	f := &Field{Name: name, Type: typ, Aliases: stringsProp(obj, "aliases"), Order: "ascending"}
+	f.Doc, _ = obj["doc"].(string)
+	if order, ok := obj["order"].(string); ok {
+		f.Order = order
+	}
+	if def, ok := obj["default"]; ok {
+		if f.Default, err = defaultValue(typ, def); err != nil {
+			return nil, fmt.Errorf("field %q: invalid default: %v", name, err)
+		}
+		f.HasDefault = true
+	}
+	return f, nil
+}
+
+func (p *parser) parseEnum(obj map[string]interface{}, namespace string) (*Schema, error) {
+	s := &Schema{Type: Enum}
+	if _, err := p.parseNamed(s, obj, namespace); err != nil {
+		return nil, err
+	}
+
+	symbols, ok := obj["symbols"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("enum %q without symbols", s.Name)
+	}
+	seen := make(map[string]bool)
+	for _, sym := range symbols {
+		str, ok := sym.(string)
+		if !ok {
+			return nil, fmt.Errorf("enum %q: invalid symbol %v", s.Name, sym)
+		}
+		if !validName.MatchString(str) {
+			return nil, fmt.Errorf("enum %q: invalid symbol %q", s.Name, str)
+		}
+		if seen[str] {
+			return nil, fmt.Errorf("enum %q: duplicate symbol %q", s.Name, str)
+		}
+		seen[str] = true
+		s.Symbols = append(s.Symbols, str)
+	}
+
+	if def, ok := obj["default"].(string); ok {
+		if !seen[def] {
+			return nil, fmt.Errorf("enum %q: default %q is not a symbol", s.Name, def)
+		}
+		s.EnumDefault = def
+	}
+	return s, nil
+}
+
+func (p *parser) parseFixed(obj map[string]interface{}, namespace string) (*Schema, error) {
+	s := &Schema{Type: Fixed}
+	if _, err := p.parseNamed(s, obj, namespace); err != nil {
+		return nil, err
+	}
+
+	size, ok := intProp(obj, "size")
+	if !ok || size < 0 {
+		return nil, fmt.Errorf("fixed %q without a valid size", s.Name)
+	}
+	s.Size = size
+	return s, nil
+}
+
+// validName matches a name, a field name, an enum symbol or a part of a full name.
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateFullName checks every dot separated part of a name.
+func validateFullName(name string) error {
+	for _, part := range strings.Split(name, ".") {
+		if !validName.MatchString(part) {
+			return fmt.Errorf("invalid name %q", name)
+		}
+	}
+	return nil
+}
+
+// fullName returns the full name of a name in the given namespace,
+// a name which contains a dot is a full name already.
+func fullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func intProp(obj map[string]interface{}, key string) (int, bool) {
+	n, ok := obj[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return 0, false
+	}
+	return int(i), true
+}
+
+func stringsProp(obj map[string]interface{}, key string) []string {
+	values, _ := obj[key].([]interface{})
+	var strs []string
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+// defaultValue converts the JSON default of a field to its native value,
+// the default of a union is a value of its first branch.
+func defaultValue(s *Schema, v interface{}) (interface{}, error) {
+	switch s.Type {
+	case Null:
+		if v != nil {
+			return nil, fmt.Errorf("%v is not null", v)
+		}
+		return nil, nil
+	case Boolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a boolean", v)
+		}
+		return b, nil
+	case Int, Long:
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a number", v)
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("%v is not an integer", v)
+		}
+		if s.Type == Int {
+			if int64(int32(i)) != i {
+				return nil, fmt.Errorf("%v overflows int", v)
+			}
+			return int32(i), nil
+		}
+		return i, nil
+	case Float, Double:
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a number", v)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, err
+		}
+		if s.Type == Float {
+			return float32(f), nil
+		}
+		return f, nil
+	case Bytes, Fixed:
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a string", v)
+		}
+		// bytes defaults are strings whose code points are the byte values.
+		var b bytes.Buffer
+		for _, r := range str {
+			if r > 0xff {
+				return nil, fmt.Errorf("%q has code points greater than 255", str)
+			}
+			b.WriteByte(byte(r))
+		}
+		if s.Type == Fixed && b.Len() != s.Size {
+			return nil, fmt.Errorf("%q is not %d bytes long", str, s.Size)
+		}
+		return b.Bytes(), nil
+	case String:
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a string", v)
+		}
+		return str, nil
+	case Enum:
+		str, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a string", v)
+		}
+		for _, sym := range s.Symbols {
+			if sym == str {
+				return str, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not a symbol of %s", str, s.Name)
+	case Array:
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v is not an array", v)
+		}
+		values := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			value, err := defaultValue(s.Items, item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case Map:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v is not an object", v)
+		}
+		values := make(map[string]interface{}, len(obj))
+		for k, item := range obj {
+			value, err := defaultValue(s.Values, item)
+			if err != nil {
+				return nil, err
+			}
+			values[k] = value
+		}
+		return values, nil
+	case Record:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%v is not an object", v)
+		}
+		values := make(map[string]interface{}, len(s.Fields))
+		for _, f := range s.Fields {
+			item, ok := obj[f.Name]
+			if !ok {
+				if !f.HasDefault {
+					return nil, fmt.Errorf("field %q is missing", f.Name)
+				}
+				values[f.Name] = f.Default
+				continue
+			}
+			value, err := defaultValue(f.Type, item)
+			if err != nil {
+				return nil, err
+			}
+			values[f.Name] = value
+		}
+		return values, nil
+	case Union:
+		if len(s.Branches) == 0 {
+			return nil, fmt.Errorf("empty union")
+		}
+		return defaultValue(s.Branches[0], v)
+	}
+
+	return nil, fmt.Errorf("unknown type %s", s.Type)
+}
@@ -0,0 +1,104 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse(userSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Record, s.Type)
+	assert.Equal(t, "com.example.User", s.Name)
+	assert.Equal(t, "com.example", s.Namespace())
+	assert.Len(t, s.Fields, 8)
+
+	role := s.Field("role")
+	assert.Equal(t, "com.example.Role", role.Type.Name)
+	assert.Equal(t, "USER", role.Default)
+
+	// the recursive reference points to the record itself.
+	friend := s.Field("friend")
+	assert.Equal(t, Union, friend.Type.Type)
+	assert.True(t, s == friend.Type.Branches[1])
+}
+
+func TestParse_References(t *testing.T) {
+	address := `{"type": "record", "name": "Address", "namespace": "com.example", "fields": [{"name": "city", "type": "string"}]}`
+	user := `{"type": "record", "name": "User", "namespace": "com.example", "fields": [{"name": "address", "type": "Address"}]}`
+
+	_, err := Parse(user)
+	assert.Error(t, err)
+
+	s, err := Parse(user, address)
+	assert.NoError(t, err)
+	assert.Equal(t, "com.example.Address", s.Field("address").Type.Name)
+}
+
+func TestParse_LogicalType(t *testing.T) {
+	s, err := Parse(`{"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}`)
+	assert.NoError(t, err)
+	assert.Equal(t, Bytes, s.Type)
+	assert.Equal(t, "decimal", s.LogicalType)
+	assert.Equal(t, 10, s.Precision)
+	assert.Equal(t, 2, s.Scale)
+
+	// the logical types out of the specification are not checked.
+	s, err = Parse(`{"type": "string", "logicalType": "my-type"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "my-type", s.LogicalType)
+}
+
+func TestParse_InvalidLogicalType(t *testing.T) {
+	tests := []struct {
+		schema string
+		typ    Type
+	}{
+		{`{"type": "string", "logicalType": "decimal", "precision": 4}`, String},
+		{`{"type": "bytes", "logicalType": "decimal"}`, Bytes},
+		{`{"type": "bytes", "logicalType": "decimal", "precision": 2, "scale": 3}`, Bytes},
+		{`{"type": "fixed", "name": "D", "size": 2, "logicalType": "decimal", "precision": 5}`, Fixed},
+		{`{"type": "int", "logicalType": "timestamp-millis"}`, Int},
+		{`{"type": "string", "logicalType": "timestamp-millis"}`, String},
+		{`{"type": "fixed", "name": "D", "size": 4, "logicalType": "duration"}`, Fixed},
+	}
+
+	// the invalid logical types are ignored, the underlying type is used.
+	for _, tt := range tests {
+		s, err := Parse(tt.schema)
+		if assert.NoError(t, err, tt.schema) {
+			assert.Equal(t, tt.typ, s.Type, tt.schema)
+			assert.Empty(t, s.LogicalType, tt.schema)
+			assert.Zero(t, s.Precision, tt.schema)
+			assert.Zero(t, s.Scale, tt.schema)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []string{
+		`{`,
+		`"unknown"`,
+		`{"type": "record", "name": "R"}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}, {"name": "a", "type": "int"}]}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int", "default": "x"}]}`,
+		`{"type": "enum", "name": "E", "symbols": ["A", "A"]}`,
+		`{"type": "fixed", "name": "F"}`,
+		`["int", "int"]`,
+		`["int", ["long"]]`,
+		`{"type": "array"}`,
+		`{"type": "record", "name": "1R", "fields": []}`,
+		`{"type": "record", "name": "R", "namespace": "com..example", "fields": []}`,
+		`{"type": "record", "name": "R", "fields": [{"name": "a-b", "type": "int"}]}`,
+		`{"type": "enum", "name": "E", "symbols": ["A B"]}`,
+	}
+
+	for _, schema := range tests {
+		_, err := Parse(schema)
+		assert.Error(t, err, schema)
+	}
+}
@@ -0,0 +1,183 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// restoreTimeout bounds the requests which put a mode back after a failure, they don't use
+// the caller's context which may be done already.
+const restoreTimeout = 10 * time.Second
+
+// Archive is a snapshot of a registry: the schemas of every subject, with their IDs, versions and references,
+// and the compatibility levels and modes. It's taken by `ExportRegistry` and replayed by `ImportRegistry`.
+type Archive struct {
+	// Compatibility is the global compatibility level.
+	Compatibility string `json:"compatibility,omitempty"`
+	// Mode is the global mode.
+	Mode     Mode             `json:"mode,omitempty"`
+	Subjects []SubjectArchive `json:"subjects"`
+}
+
+// SubjectArchive is the part of an `Archive` of a subject.
+type SubjectArchive struct {
+	Subject string `json:"subject"`
+	// Compatibility is the compatibility level of the subject, empty when it uses the global one.
+	Compatibility string `json:"compatibility,omitempty"`
+	// Mode is the mode of the subject, empty when it has none of its own.
+	Mode Mode `json:"mode,omitempty"`
+	// Schemas are the versions of the subject, oldest first, the soft-deleted ones are not archived.
+	Schemas []Schema `json:"schemas"`
+}
+
+// ExportRegistry takes a snapshot of the registry, it walks the subjects, their versions, compatibility levels and modes.
+func ExportRegistry(r Registry) (Archive, error) {
+	return ExportRegistryContext(context.Background(), r)
+}
+
+// ExportRegistryContext same as `ExportRegistry` but it accepts a context to control the lifetime of the requests.
+func ExportRegistryContext(ctx context.Context, r Registry) (Archive, error) {
+	var archive Archive
+
+	cfg, err := r.GetConfigContext(ctx, "")
+	if err != nil {
+		return archive, err
+	}
+	if level, err := cfg.Level(); err == nil {
+		archive.Compatibility = level.String()
+	}
+	if archive.Mode, err = r.GetModeContext(ctx, ""); err != nil {
+		return archive, err
+	}
+
+	subjects, err := r.SubjectsContext(ctx)
+	if err != nil {
+		return archive, err
+	}
+	sort.Strings(subjects)
+
+	archive.Subjects = make([]SubjectArchive, 0, len(subjects))
+	for _, subject := range subjects {
+		sa, err := exportSubject(ctx, r, subject)
+		if err != nil {
+			return archive, fmt.Errorf("subject %s: %v", subject, err)
+		}
+		archive.Subjects = append(archive.Subjects, sa)
+	}
+	return archive, nil
+}
+
+func exportSubject(ctx context.Context, r Registry, subject string) (SubjectArchive, error) {
+	sa := SubjectArchive{Subject: subject}
+
+	cfg, err := r.GetConfigContext(ctx, subject)
+	if err != nil {
+		return sa, err
+	}
+	if level, err := cfg.Level(); err == nil {
+		sa.Compatibility = level.String()
+	}
+	// a subject without a mode of its own has none in the archive, it keeps following the global mode.
+	if sa.Mode, err = r.GetModeContext(ctx, subject); err != nil {
+		return sa, err
+	}
+
+	versions, err := r.VersionsContext(ctx, subject)
+	if err != nil {
+		return sa, err
+	}
+	sort.Ints(versions)
+
+	sa.Schemas = make([]Schema, 0, len(versions))
+	for _, version := range versions {
+		schema, err := r.GetSchemaBySubjectContext(ctx, subject, version)
+		if err != nil {
+			return sa, err
+		}
+		sa.Schemas = append(sa.Schemas, schema.withDefaultType())
+	}
+	return sa, nil
+}
+
+// ImportRegistry replays the archive into the registry, the schemas keep their IDs and versions. The registry
+// is switched to `Import` mode, which it accepts only when it has no schemas, and then to the archived
+// modes, the compatibility levels are restored too. When the import fails, the registry is switched back
+// to its previous global mode.
+func ImportRegistry(r Registry, archive Archive) error {
+	return ImportRegistryContext(context.Background(), r, archive)
+}
+
+// ImportRegistryContext same as `ImportRegistry` but it accepts a context to control the lifetime of the requests.
+func ImportRegistryContext(ctx context.Context, r Registry, archive Archive) (err error) {
+	previous, err := r.GetModeContext(ctx, "")
+	if err != nil {
+		return err
+	}
+	if _, err := r.SetModeContext(ctx, Import, ""); err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		restoreCtx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+		defer cancel()
+		if _, restoreErr := r.SetModeForcedContext(restoreCtx, previous, ""); restoreErr != nil {
+			err = fmt.Errorf("%v, the global mode is still %s: %v", err, Import, restoreErr)
+		}
+	}()
+
+	// the schemas are imported in the order of their IDs, so the referenced schemas,
+	// which were registered first, are there when the schemas referring to them are imported.
+	var schemas []Schema
+	for _, sa := range archive.Subjects {
+		for _, schema := range sa.Schemas {
+			schema.Subject = sa.Subject
+			schemas = append(schemas, schema)
+		}
+	}
+	sort.SliceStable(schemas, func(i, j int) bool {
+		if schemas[i].ID != schemas[j].ID {
+			return schemas[i].ID < schemas[j].ID
+		}
+		return schemas[i].Version < schemas[j].Version
+	})
+
+	for _, schema := range schemas {
+		if _, err := r.ImportSchemaContext(ctx, schema.Subject, schema); err != nil {
+			return fmt.Errorf("subject %s version %d: %v", schema.Subject, schema.Version, err)
+		}
+	}
+
+	for _, sa := range archive.Subjects {
+		if err := restoreSettings(ctx, r, sa.Subject, sa.Compatibility, sa.Mode); err != nil {
+			return fmt.Errorf("subject %s: %v", sa.Subject, err)
+		}
+	}
+
+	mode := archive.Mode
+	if mode == "" {
+		mode = ReadWrite
+	}
+	return restoreSettings(ctx, r, "", archive.Compatibility, mode)
+}
+
+func restoreSettings(ctx context.Context, r Registry, subject, compatibility string, mode Mode) error {
+	if compatibility != "" {
+		level, err := ParseCompatibilityLevel(compatibility)
+		if err != nil {
+			return err
+		}
+		if _, err := r.SetConfigLevelContext(ctx, level, subject); err != nil {
+			return err
+		}
+	}
+	if mode != "" {
+		if _, err := r.SetModeForcedContext(ctx, mode, subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package schemaregistry_test
+
+import (
+	"testing"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/bjornm82/schema-registry/registrytest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	userV1 = `{"type":"record","name":"User","fields":[{"name":"name","type":"string"}]}`
+	userV2 = `{"type":"record","name":"User","fields":[{"name":"name","type":"string"},{"name":"age","type":"int","default":0}]}`
+)
+
+// newTestRegistry returns a client of a new fake registry.
+func newTestRegistry(t *testing.T) (*registrytest.Server, *schemaregistry.Client) {
+	srv := registrytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	c, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, c
+}
+
+func TestExportImportRegistry(t *testing.T) {
+	_, src := newTestRegistry(t)
+	src.RegisterNewSchema("users-value", userV1)
+	src.RegisterNewSchema("users-value", userV2)
+	src.RegisterNewSchema("address-value", `{"type":"record","name":"Address","fields":[{"name":"city","type":"string"}]}`)
+	src.RegisterSchema("orders-value", schemaregistry.Schema{
+		Schema:     `{"type":"record","name":"Order","fields":[{"name":"address","type":"Address"}]}`,
+		References: []schemaregistry.SchemaReference{{Name: "Address", Subject: "address-value", Version: 1}},
+	})
+	// a schema registered under a second subject keeps its ID.
+	src.RegisterNewSchema("customers-value", userV1)
+	src.SetConfigLevel(schemaregistry.Full, "users-value")
+	src.SetMode(schemaregistry.ReadOnly, "orders-value")
+	// a mode of its own is kept even when it's the global one.
+	src.SetMode(schemaregistry.ReadWrite, "customers-value")
+
+	archive, err := schemaregistry.ExportRegistry(src)
+	assert.NoError(t, err)
+	assert.Equal(t, "BACKWARD", archive.Compatibility)
+	assert.Equal(t, schemaregistry.ReadWrite, archive.Mode)
+	if assert.Len(t, archive.Subjects, 4) {
+		assert.Equal(t, "address-value", archive.Subjects[0].Subject)
+		assert.Equal(t, schemaregistry.Mode(""), archive.Subjects[0].Mode)
+		assert.Equal(t, schemaregistry.ReadWrite, archive.Subjects[1].Mode)
+		assert.Equal(t, schemaregistry.ReadOnly, archive.Subjects[2].Mode)
+		assert.Equal(t, "FULL", archive.Subjects[3].Compatibility)
+		assert.Len(t, archive.Subjects[3].Schemas, 2)
+	}
+
+	_, dst := newTestRegistry(t)
+	// the IDs of the target are taken by another schema, so they would not be kept.
+	dst.RegisterNewSchema("other-value", `"string"`)
+	assert.Error(t, schemaregistry.ImportRegistry(dst, archive))
+
+	_, dst = newTestRegistry(t)
+	assert.NoError(t, schemaregistry.ImportRegistry(dst, archive))
+
+	restored, err := schemaregistry.ExportRegistry(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, archive, restored)
+}
+
+func TestImportRegistry_Failed(t *testing.T) {
+	archive := schemaregistry.Archive{
+		Subjects: []schemaregistry.SubjectArchive{{
+			Subject: "users-value",
+			Schemas: []schemaregistry.Schema{
+				{Schema: userV1, ID: 1, Version: 1},
+				{Schema: "{not json", ID: 2, Version: 2},
+			},
+		}},
+	}
+
+	_, r := newTestRegistry(t)
+	assert.Error(t, schemaregistry.ImportRegistry(r, archive))
+
+	// the registry is not left in import mode.
+	mode, err := r.GetMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.ReadWrite, mode)
+}
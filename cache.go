@@ -0,0 +1,401 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheSize is the default maximum number of entries of each cache of a `CachedClient`.
+const DefaultCacheSize = 10000
+
+// DefaultLatestSchemaTTL is the default time a latest schema of a subject is cached by a `CachedClient`.
+const DefaultLatestSchemaTTL = time.Minute
+
+// DefaultFetchTimeout is the default time limit of a lookup a `CachedClient` sends to the registry.
+const DefaultFetchTimeout = 30 * time.Second
+
+type (
+	// CachedClient is a `Registry` which caches the schema lookups of another one, usually a `Client`.
+	//
+	// Schemas by ID, schemas by subject and version, the IDs of the registered schemas and the schemas
+	// found by `LookupSchema` are immutable and they are cached until they are evicted by newer entries, the latest schema of a subject
+	// is cached for a limited time only. Deleting a subject or a version through the `CachedClient`
+	// drops the cached entries of the subject. Concurrent lookups of the same missing entry
+	// are sent to the registry once, all callers share the same result, a caller whose context is done
+	// stops waiting without failing the others.
+	//
+	// All the other methods are served by the embedded `Registry`.
+	CachedClient struct {
+		Registry
+
+		ids           *lruCache // id -> Schema, without subject and version
+		versions      *lruCache // subjectVersionKey -> Schema
+		registrations *lruCache // subjectSchemaKey -> id
+		lookups       *lruCache // subjectSchemaKey -> Schema, the found ones only
+		latest        *lruCache // subject -> latestEntry
+		latestTTL     time.Duration
+
+		flight flightGroup
+
+		// generations count the times the cached entries of a subject were dropped, a lookup which
+		// was in flight meanwhile doesn't cache its result, it may be stale.
+		mu          sync.Mutex
+		generations map[string]uint64
+	}
+
+	// CacheOption describes an optional configurator that can be passed on `NewCachedClient`.
+	CacheOption func(*cacheConfig)
+
+	cacheConfig struct {
+		size         int
+		latestTTL    time.Duration
+		fetchTimeout time.Duration
+	}
+
+	subjectVersionKey struct {
+		subject string
+		version int
+	}
+
+	subjectSchemaKey struct {
+		subject    string
+		schema     string
+		schemaType SchemaType
+		references string
+	}
+
+	latestEntry struct {
+		schema  Schema
+		expires time.Time
+	}
+
+	// flight keys, one type per lookup so they never collide.
+	idFlightKey           int
+	versionFlightKey      subjectVersionKey
+	registrationFlightKey subjectSchemaKey
+	lookupFlightKey       subjectSchemaKey
+	latestFlightKey       string
+)
+
+// WithCacheSize sets the maximum number of entries of each cache, the least recently used entries are evicted first.
+// A size less or equal to zero means that the caches are unbounded.
+func WithCacheSize(size int) CacheOption {
+	return func(cfg *cacheConfig) {
+		cfg.size = size
+	}
+}
+
+// WithLatestSchemaTTL sets the time the latest schema of a subject is cached, zero disables its caching.
+func WithLatestSchemaTTL(ttl time.Duration) CacheOption {
+	return func(cfg *cacheConfig) {
+		cfg.latestTTL = ttl
+	}
+}
+
+// WithFetchTimeout sets the time limit of a lookup sent to the registry, zero means no limit. The lookups are
+// shared by the concurrent callers, so they don't end with the context of a caller but with this limit.
+func WithFetchTimeout(timeout time.Duration) CacheOption {
+	return func(cfg *cacheConfig) {
+		cfg.fetchTimeout = timeout
+	}
+}
+
+// NewCachedClient returns a `CachedClient` which caches the lookups of "r".
+func NewCachedClient(r Registry, options ...CacheOption) *CachedClient {
+	cfg := cacheConfig{size: DefaultCacheSize, latestTTL: DefaultLatestSchemaTTL, fetchTimeout: DefaultFetchTimeout}
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	return &CachedClient{
+		Registry:      r,
+		ids:           newLRUCache(cfg.size),
+		versions:      newLRUCache(cfg.size),
+		registrations: newLRUCache(cfg.size),
+		lookups:       newLRUCache(cfg.size),
+		latest:        newLRUCache(cfg.size),
+		latestTTL:     cfg.latestTTL,
+		flight:        flightGroup{timeout: cfg.fetchTimeout},
+		generations:   make(map[string]uint64),
+	}
+}
+
+// Purge drops all the cached entries.
+func (cc *CachedClient) Purge() {
+	cc.ids.purge()
+	cc.versions.purge()
+	cc.registrations.purge()
+	cc.lookups.purge()
+	cc.latest.purge()
+}
+
+func newSubjectSchemaKey(subject string, s Schema) subjectSchemaKey {
+	var refs strings.Builder
+	for _, ref := range s.References {
+		fmt.Fprintf(&refs, "%s=%s:%d;", ref.Name, ref.Subject, ref.Version)
+	}
+	return subjectSchemaKey{subject, s.Schema, s.withDefaultType().SchemaType, refs.String()}
+}
+
+// generation returns the generation of the cached entries of the subject.
+func (cc *CachedClient) generation(subject string) uint64 {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.generations[subject]
+}
+
+// ifCurrent runs add when the cached entries of the subject were not dropped since the generation.
+func (cc *CachedClient) ifCurrent(subject string, gen uint64, add func()) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.generations[subject] == gen {
+		add()
+	}
+}
+
+// cacheSchema caches a schema returned by the registry.
+func (cc *CachedClient) cacheSchema(s Schema) {
+	s = s.withDefaultType()
+	if s.ID > 0 {
+		cc.ids.add(s.ID, Schema{Schema: s.Schema, ID: s.ID, SchemaType: s.SchemaType, References: s.References})
+	}
+	if s.Subject != "" && s.Version > 0 {
+		cc.versions.add(subjectVersionKey{s.Subject, s.Version}, s)
+	}
+	if s.Subject != "" && s.ID > 0 {
+		cc.registrations.add(newSubjectSchemaKey(s.Subject, s), s.ID)
+	}
+}
+
+// RegisterNewSchema registers a schema, the ID of an already registered schema is served from the cache.
+func (cc *CachedClient) RegisterNewSchema(subject string, avroSchema string) (int, error) {
+	return cc.RegisterNewSchemaContext(context.Background(), subject, avroSchema)
+}
+
+// RegisterNewSchemaContext same as `RegisterNewSchema` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) RegisterNewSchemaContext(ctx context.Context, subject string, avroSchema string) (int, error) {
+	return cc.RegisterSchemaContext(ctx, subject, Schema{Schema: avroSchema})
+}
+
+// RegisterSchema registers a schema of any type, the ID of an already registered schema is served from the cache.
+func (cc *CachedClient) RegisterSchema(subject string, schema Schema) (int, error) {
+	return cc.RegisterSchemaContext(context.Background(), subject, schema)
+}
+
+// RegisterSchemaContext same as `RegisterSchema` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) RegisterSchemaContext(ctx context.Context, subject string, schema Schema) (int, error) {
+	key := newSubjectSchemaKey(subject, schema)
+	if id, ok := cc.registrations.get(key); ok {
+		return id.(int), nil
+	}
+
+	id, err := cc.flight.do(ctx, registrationFlightKey(key), func(ctx context.Context) (interface{}, error) {
+		gen := cc.generation(subject)
+		id, err := cc.Registry.RegisterSchemaContext(ctx, subject, schema)
+		if err != nil {
+			return 0, err
+		}
+		cc.ids.add(id, Schema{Schema: schema.Schema, ID: id, SchemaType: key.schemaType, References: schema.References})
+		cc.ifCurrent(subject, gen, func() { cc.registrations.add(key, id) })
+		return id, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id.(int), nil
+}
+
+// GetSchemaByID returns the schema string identified by the id, it's served from the cache when possible.
+func (cc *CachedClient) GetSchemaByID(subjectID int) (string, error) {
+	return cc.GetSchemaByIDContext(context.Background(), subjectID)
+}
+
+// GetSchemaByIDContext same as `GetSchemaByID` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) GetSchemaByIDContext(ctx context.Context, subjectID int) (string, error) {
+	s, err := cc.GetSchemaDetailsByIDContext(ctx, subjectID)
+	return s.Schema, err
+}
+
+// GetSchemaDetailsByID returns the schema identified by the id along with its type, it's served from the cache when possible.
+func (cc *CachedClient) GetSchemaDetailsByID(subjectID int) (Schema, error) {
+	return cc.GetSchemaDetailsByIDContext(context.Background(), subjectID)
+}
+
+// GetSchemaDetailsByIDContext same as `GetSchemaDetailsByID` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) GetSchemaDetailsByIDContext(ctx context.Context, subjectID int) (Schema, error) {
+	if s, ok := cc.ids.get(subjectID); ok {
+		return s.(Schema), nil
+	}
+
+	s, err := cc.flight.do(ctx, idFlightKey(subjectID), func(ctx context.Context) (interface{}, error) {
+		s, err := cc.Registry.GetSchemaDetailsByIDContext(ctx, subjectID)
+		if err != nil {
+			return Schema{}, err
+		}
+		cc.ids.add(subjectID, s)
+		return s, nil
+	})
+	if err != nil {
+		return Schema{}, err
+	}
+
+	return s.(Schema), nil
+}
+
+// GetSchemaBySubject returns the schema for a particular subject and version, it's served from the cache when possible.
+func (cc *CachedClient) GetSchemaBySubject(subject string, versionID int) (Schema, error) {
+	return cc.GetSchemaBySubjectContext(context.Background(), subject, versionID)
+}
+
+// GetSchemaBySubjectContext same as `GetSchemaBySubject` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) GetSchemaBySubjectContext(ctx context.Context, subject string, versionID int) (Schema, error) {
+	key := subjectVersionKey{subject, versionID}
+	if s, ok := cc.versions.get(key); ok {
+		return s.(Schema), nil
+	}
+
+	s, err := cc.flight.do(ctx, versionFlightKey(key), func(ctx context.Context) (interface{}, error) {
+		gen := cc.generation(subject)
+		s, err := cc.Registry.GetSchemaBySubjectContext(ctx, subject, versionID)
+		if err != nil {
+			return Schema{}, err
+		}
+		cc.ifCurrent(subject, gen, func() { cc.cacheSchema(s) })
+		return s, nil
+	})
+	if err != nil {
+		return Schema{}, err
+	}
+
+	return s.(Schema), nil
+}
+
+// GetLatestSchema returns the latest version of a schema, it's cached for the configured TTL, see `WithLatestSchemaTTL`.
+func (cc *CachedClient) GetLatestSchema(subject string) (Schema, error) {
+	return cc.GetLatestSchemaContext(context.Background(), subject)
+}
+
+// GetLatestSchemaContext same as `GetLatestSchema` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) GetLatestSchemaContext(ctx context.Context, subject string) (Schema, error) {
+	if cc.latestTTL <= 0 {
+		return cc.Registry.GetLatestSchemaContext(ctx, subject)
+	}
+
+	if entry, ok := cc.latest.get(subject); ok {
+		if e := entry.(latestEntry); time.Now().Before(e.expires) {
+			return e.schema, nil
+		}
+		cc.latest.remove(subject)
+	}
+
+	s, err := cc.flight.do(ctx, latestFlightKey(subject), func(ctx context.Context) (interface{}, error) {
+		gen := cc.generation(subject)
+		s, err := cc.Registry.GetLatestSchemaContext(ctx, subject)
+		if err != nil {
+			return Schema{}, err
+		}
+		cc.ifCurrent(subject, gen, func() {
+			cc.cacheSchema(s)
+			cc.latest.add(subject, latestEntry{schema: s, expires: time.Now().Add(cc.latestTTL)})
+		})
+		return s, nil
+	})
+	if err != nil {
+		return Schema{}, err
+	}
+
+	return s.(Schema), nil
+}
+
+// LookupSchema checks if the schema is registered under the subject, a found schema is served from the cache.
+// A schema which is not found is looked up again on the next call.
+func (cc *CachedClient) LookupSchema(subject string, schema Schema) (bool, Schema, error) {
+	return cc.LookupSchemaContext(context.Background(), subject, schema)
+}
+
+// LookupSchemaContext same as `LookupSchema` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) LookupSchemaContext(ctx context.Context, subject string, schema Schema) (bool, Schema, error) {
+	key := newSubjectSchemaKey(subject, schema)
+	if s, ok := cc.lookups.get(key); ok {
+		return true, s.(Schema), nil
+	}
+
+	s, err := cc.flight.do(ctx, lookupFlightKey(key), func(ctx context.Context) (interface{}, error) {
+		gen := cc.generation(subject)
+		found, s, err := cc.Registry.LookupSchemaContext(ctx, subject, schema)
+		if err != nil || !found {
+			return nil, err
+		}
+		cc.ifCurrent(subject, gen, func() {
+			cc.cacheSchema(s)
+			cc.registrations.add(key, s.ID)
+			cc.lookups.add(key, s)
+		})
+		return s, nil
+	})
+	if err != nil || s == nil {
+		return false, Schema{}, err
+	}
+
+	return true, s.(Schema), nil
+}
+
+// forgetSubject drops the cached versions, registrations, lookups and latest schema of the subject,
+// the lookups in flight don't cache their results.
+func (cc *CachedClient) forgetSubject(subject string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.generations[subject]++
+	cc.versions.removeIf(func(key interface{}) bool { return key.(subjectVersionKey).subject == subject })
+	cc.registrations.removeIf(func(key interface{}) bool { return key.(subjectSchemaKey).subject == subject })
+	cc.lookups.removeIf(func(key interface{}) bool { return key.(subjectSchemaKey).subject == subject })
+	cc.latest.remove(subject)
+}
+
+// DeleteSubject soft-deletes the subject and drops its cached entries.
+func (cc *CachedClient) DeleteSubject(subject string) ([]int, error) {
+	return cc.DeleteSubjectContext(context.Background(), subject)
+}
+
+// DeleteSubjectContext same as `DeleteSubject` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) DeleteSubjectContext(ctx context.Context, subject string) ([]int, error) {
+	defer cc.forgetSubject(subject)
+	return cc.Registry.DeleteSubjectContext(ctx, subject)
+}
+
+// PermanentlyDeleteSubject permanently deletes the subject and drops its cached entries.
+func (cc *CachedClient) PermanentlyDeleteSubject(subject string) ([]int, error) {
+	return cc.PermanentlyDeleteSubjectContext(context.Background(), subject)
+}
+
+// PermanentlyDeleteSubjectContext same as `PermanentlyDeleteSubject` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) PermanentlyDeleteSubjectContext(ctx context.Context, subject string) ([]int, error) {
+	defer cc.forgetSubject(subject)
+	return cc.Registry.PermanentlyDeleteSubjectContext(ctx, subject)
+}
+
+// DeleteSchemaVersion soft-deletes a version of the subject and drops the cached entries of the subject.
+func (cc *CachedClient) DeleteSchemaVersion(subject string, versionID int) (int, error) {
+	return cc.DeleteSchemaVersionContext(context.Background(), subject, versionID)
+}
+
+// DeleteSchemaVersionContext same as `DeleteSchemaVersion` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) DeleteSchemaVersionContext(ctx context.Context, subject string, versionID int) (int, error) {
+	defer cc.forgetSubject(subject)
+	return cc.Registry.DeleteSchemaVersionContext(ctx, subject, versionID)
+}
+
+// PermanentlyDeleteSchemaVersion permanently deletes a version of the subject and drops the cached entries of the subject.
+func (cc *CachedClient) PermanentlyDeleteSchemaVersion(subject string, versionID int) (int, error) {
+	return cc.PermanentlyDeleteSchemaVersionContext(context.Background(), subject, versionID)
+}
+
+// PermanentlyDeleteSchemaVersionContext same as `PermanentlyDeleteSchemaVersion` but it accepts a context to control the request's lifetime.
+func (cc *CachedClient) PermanentlyDeleteSchemaVersionContext(ctx context.Context, subject string, versionID int) (int, error) {
+	defer cc.forgetSubject(subject)
+	return cc.Registry.PermanentlyDeleteSchemaVersionContext(ctx, subject, versionID)
+}
@@ -0,0 +1,268 @@
+package schemaregistry
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingClient wraps the client's http doer and counts the requests sent.
+func countingClient(c *Client) (*Client, *int32) {
+	var calls int32
+	next := c.client
+	c.client = D(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return next.Do(req)
+	})
+	return c, &calls
+}
+
+func TestCachedClient_GetSchemaByID(t *testing.T) {
+	c, calls := countingClient(httpSuccess(t, http.MethodGet, "/schemas/ids/7", nil, schemaOnlyJSON{`"string"`}))
+	cc := NewCachedClient(c)
+
+	for i := 0; i < 3; i++ {
+		s, err := cc.GetSchemaByID(7)
+		assert.NoError(t, err)
+		assert.Equal(t, `"string"`, s)
+	}
+	assert.Equal(t, int32(1), *calls)
+
+	cc.Purge()
+	_, err := cc.GetSchemaByID(7)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), *calls)
+}
+
+func TestCachedClient_GetSchemaBySubject(t *testing.T) {
+	sIn := Schema{Schema: `"string"`, Subject: "mysubject", Version: 2, ID: 7, SchemaType: Avro}
+	c, calls := countingClient(httpSuccess(t, http.MethodGet, "/subjects/mysubject/versions/2", nil, sIn))
+	cc := NewCachedClient(c)
+
+	for i := 0; i < 3; i++ {
+		s, err := cc.GetSchemaBySubject("mysubject", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, sIn, s)
+	}
+
+	// the schema by id and the registration are known too.
+	s, err := cc.GetSchemaByID(7)
+	assert.NoError(t, err)
+	assert.Equal(t, `"string"`, s)
+	id, err := cc.RegisterNewSchema("mysubject", `"string"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+
+	assert.Equal(t, int32(1), *calls)
+}
+
+func TestCachedClient_RegisterNewSchema(t *testing.T) {
+	c, calls := countingClient(httpSuccess(t, http.MethodPost, "/subjects/mysubject/versions", schemaOnlyJSON{`"string"`}, idOnlyJSON{ID: 3}))
+	cc := NewCachedClient(c)
+
+	for i := 0; i < 3; i++ {
+		id, err := cc.RegisterNewSchema("mysubject", `"string"`)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, id)
+	}
+	assert.Equal(t, int32(1), *calls)
+}
+
+func TestCachedClient_LookupSchema(t *testing.T) {
+	sIn := Schema{Schema: `"string"`, Subject: "mysubject", Version: 2, ID: 7, SchemaType: Avro}
+	c, calls := countingClient(httpSuccess(t, http.MethodPost, "/subjects/mysubject", schemaOnlyJSON{`"string"`}, sIn))
+	cc := NewCachedClient(c)
+
+	for i := 0; i < 3; i++ {
+		found, s, err := cc.LookupSchema("mysubject", Schema{Schema: `"string"`})
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, sIn, s)
+	}
+
+	// the registration is known too.
+	id, err := cc.RegisterNewSchema("mysubject", `"string"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+	assert.Equal(t, int32(1), *calls)
+
+	// a schema which is not found is not cached.
+	c, calls = countingClient(httpError(t, http.StatusNotFound, schemaNotFoundCode, "not found"))
+	cc = NewCachedClient(c)
+	for i := 0; i < 2; i++ {
+		found, _, err := cc.LookupSchema("mysubject", Schema{Schema: `"string"`})
+		assert.NoError(t, err)
+		assert.False(t, found)
+	}
+	assert.Equal(t, int32(2), *calls)
+}
+
+func TestCachedClient_GetLatestSchema(t *testing.T) {
+	sIn := Schema{Schema: `"string"`, Subject: "mysubject", Version: 2, ID: 7}
+	c, calls := countingClient(httpSuccess(t, http.MethodGet, "/subjects/mysubject/versions/latest", nil, sIn))
+	cc := NewCachedClient(c, WithLatestSchemaTTL(20*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		_, err := cc.GetLatestSchema("mysubject")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), *calls)
+
+	time.Sleep(30 * time.Millisecond)
+	_, err := cc.GetLatestSchema("mysubject")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), *calls)
+
+	cc = NewCachedClient(c, WithLatestSchemaTTL(0))
+	_, err = cc.GetLatestSchema("mysubject")
+	assert.NoError(t, err)
+	_, err = cc.GetLatestSchema("mysubject")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4), *calls)
+}
+
+func TestCachedClient_ErrorsNotCached(t *testing.T) {
+	c, calls := countingClient(httpError(t, http.StatusNotFound, schemaNotFoundCode, "not found"))
+	cc := NewCachedClient(c)
+
+	_, err := cc.GetSchemaByID(7)
+	assert.True(t, IsSchemaNotFound(err))
+	_, err = cc.GetSchemaByID(7)
+	assert.True(t, IsSchemaNotFound(err))
+	assert.Equal(t, int32(2), *calls)
+}
+
+func TestCachedClient_ConcurrentMisses(t *testing.T) {
+	release := make(chan struct{})
+	c := httpSuccess(t, http.MethodGet, "/schemas/ids/7", nil, schemaOnlyJSON{`"string"`})
+	next := c.client
+	var calls int32
+	c.client = D(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return next.Do(req)
+	})
+	cc := NewCachedClient(c)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := cc.GetSchemaByID(7)
+			assert.NoError(t, err)
+			assert.Equal(t, `"string"`, s)
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCachedClient_ConcurrentMissCanceled(t *testing.T) {
+	release := make(chan struct{})
+	c := httpSuccess(t, http.MethodGet, "/schemas/ids/7", nil, schemaOnlyJSON{`"string"`})
+	next := c.client
+	c.client = D(func(req *http.Request) (*http.Response, error) {
+		<-release
+		return next.Do(req)
+	})
+	cc := NewCachedClient(c)
+
+	// the first caller gives up, the second one still gets the shared result.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := cc.GetSchemaByIDContext(ctx, 7)
+		first <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	second := make(chan string)
+	go func() {
+		s, err := cc.GetSchemaByID(7)
+		assert.NoError(t, err)
+		second <- s
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-first)
+	close(release)
+	assert.Equal(t, `"string"`, <-second)
+}
+
+func TestCachedClient_ForgetSubjectInFlight(t *testing.T) {
+	sIn := Schema{Schema: `"string"`, Subject: "mysubject", Version: 2, ID: 7, SchemaType: Avro}
+	c, calls := countingClient(httpSuccess(t, http.MethodGet, "/subjects/mysubject/versions/latest", nil, sIn))
+	release := make(chan struct{})
+	next := c.client
+	c.client = D(func(req *http.Request) (*http.Response, error) {
+		<-release
+		return next.Do(req)
+	})
+	cc := NewCachedClient(c)
+
+	// the subject is dropped while its latest schema is fetched, the fetched one may be stale.
+	done := make(chan struct{})
+	go func() {
+		_, err := cc.GetLatestSchema("mysubject")
+		assert.NoError(t, err)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cc.forgetSubject("mysubject")
+	close(release)
+	<-done
+
+	_, err := cc.GetLatestSchema("mysubject")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestFlightGroup_Timeout(t *testing.T) {
+	g := flightGroup{timeout: 10 * time.Millisecond}
+	_, err := g.do(context.Background(), 1, func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestFlightGroup_Panic(t *testing.T) {
+	var g flightGroup
+	fn := func(ctx context.Context) (interface{}, error) { panic("boom") }
+
+	assert.PanicsWithValue(t, "boom", func() { g.do(context.Background(), 1, fn) })
+	// the key is released, the next call runs again.
+	v, err := g.do(context.Background(), 1, func(ctx context.Context) (interface{}, error) { return 2, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+}
+
+func TestLRUCache(t *testing.T) {
+	c := newLRUCache(2)
+	c.add(1, "a")
+	c.add(2, "b")
+	c.get(1)
+	c.add(3, "c")
+
+	_, ok := c.get(2)
+	assert.False(t, ok, "least recently used entry must be evicted")
+	v, ok := c.get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "a", v)
+	assert.Equal(t, 2, c.len())
+
+	c.remove(1)
+	assert.Equal(t, 1, c.len())
+	c.purge()
+	assert.Equal(t, 0, c.len())
+}
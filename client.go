@@ -4,14 +4,17 @@ package schemaregistry
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -20,6 +23,8 @@ const (
 	errHostEmpty                  = "host can not be empty"
 	errHostNotContainForwardSlash = "host can not contain a /"
 	errHostNotContainQuestionmark = "host can not contain a ?"
+	errURLScheme                  = "url scheme must be http or https"
+	errURLQuery                   = "url can not contain a query or a fragment"
 )
 
 // DefaultURL is the address where a local schema registry listens by default.
@@ -33,8 +38,27 @@ type (
 	Client struct {
 		baseURL string
 
+		// endpoints are the registry nodes of a failover client, nil when the client talks to a single node.
+		endpoints *endpointPool
+
+		// basicAuth and tokens authenticate the requests, see `WithBasicAuth` and `WithBearerToken`.
+		basicAuth *basicAuth
+		tokens    TokenSource
+
+		// retry is the policy applied on failed requests, nil means that every request is attempted only once.
+		retry *RetryPolicy
+
 		// the client is created on the `NewClient` function, it can be customized via options.
 		client httpDoer
+
+		// validate rejects the malformed Avro schemas before they are registered, see `WithSchemaValidation`.
+		validate bool
+
+		// tls is the TLS configuration of the http client's transport, see `WithCACert` and `WithClientCert`.
+		tls *tls.Config
+
+		// err is the first error of the options, reported by the constructor.
+		err error
 	}
 
 	// Option describes an optional runtime configurator that can be passed on `NewClient`.
@@ -48,12 +72,16 @@ type (
 		Schema string `json:"schema"`
 	}
 
-	idOnlyJSON struct {
-		ID int `json:"id"`
+	schemaRequestJSON struct {
+		Schema     string            `json:"schema"`
+		SchemaType SchemaType        `json:"schemaType,omitempty"`
+		References []SchemaReference `json:"references,omitempty"`
+		ID         int               `json:"id,omitempty"`
+		Version    int               `json:"version,omitempty"`
 	}
 
-	isCompatibleJSON struct {
-		IsCompatible bool `json:"is_compatible"`
+	idOnlyJSON struct {
+		ID int `json:"id"`
 	}
 )
 
@@ -89,76 +117,124 @@ func getTransportLayer(httpClient *http.Client, timeout time.Duration) (t http.R
 	return httpTransport
 }
 
-// formatBaseURL will try to make sure that the schema:host:port pattern is followed on the `baseURL` field.
-func formatBaseURL(host string, port int, useSSL bool) (string, error) {
+// hostURL returns the url of a registry served at the root of the host. The port 80, or no port without SSL,
+// is left out of the url, SSL or the port 443 mean https and no port with SSL is the port 443.
+func hostURL(host string, port int, useSSL bool) (*url.URL, error) {
 	if host == "" {
-		return "", errors.New(errHostEmpty)
+		return nil, errors.New(errHostEmpty)
 	}
 
 	if strings.Contains(host, "/") {
-		return "", errors.New(errHostNotContainForwardSlash)
+		return nil, errors.New(errHostNotContainForwardSlash)
 	}
 
 	if strings.Contains(host, "?") {
-		return "", errors.New(errHostNotContainQuestionmark)
+		return nil, errors.New(errHostNotContainQuestionmark)
 	}
 
-	var scheme = "http"
-
-	if useSSL {
-		scheme = "https"
+	u := &url.URL{Scheme: "http", Host: host}
+	if useSSL || port == 443 {
+		u.Scheme = "https"
 	}
-
-	if port == 443 {
-		scheme = "https"
-	}
-
 	if port == 0 && useSSL {
-		scheme = "https"
 		port = 443
 	}
-
-	if port == 0 && !useSSL {
-		scheme = "http"
-		return fmt.Sprintf("%s://%s", scheme, host), nil
-	}
-
-	if port == 80 && !useSSL {
-		scheme = "http"
-		return fmt.Sprintf("%s://%s", scheme, host), nil
+	if port != 0 && port != 80 {
+		u.Host = net.JoinHostPort(host, strconv.Itoa(port))
 	}
-
-	if port == 80 && useSSL {
-		scheme = "https"
-		return fmt.Sprintf("%s://%s", scheme, host), nil
-	}
-
-	return fmt.Sprintf("%s://%s:%d", scheme, host, port), nil
+	return u, nil
 }
 
 // NewClient creates & returns a new Registry Schema Client
 // based on the passed url and the options.
 func NewClient(host string, port int, useSSL bool, options ...Option) (*Client, error) {
-	baseURL, err := formatBaseURL(host, port, useSSL)
+	u, err := hostURL(host, port, useSSL)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := url.Parse(baseURL); err != nil {
+	return NewClientFromURL(u.String(), options...)
+}
+
+// NewClientFromURL creates & returns a new Registry Schema Client based on the passed url and the options.
+// The url may contain a base path, e.g. "https://gw.example.com/schema-registry",
+// when the registry is served under a path prefix, and a "user:password@" userinfo,
+// which is used for basic authentication unless another authentication option is passed.
+func NewClientFromURL(rawURL string, options ...Option) (*Client, error) {
+	baseURL, userinfo, err := parseBaseURL(rawURL)
+	if err != nil {
 		return nil, err
 	}
 
 	c := &Client{baseURL: baseURL}
+	if err := c.init(withUserinfo(userinfo, options)); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// parseBaseURL validates the url of a registry and returns it without the userinfo and the trailing slash.
+func parseBaseURL(rawURL string) (string, *url.Userinfo, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", nil, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", nil, fmt.Errorf("%s: %s", errURLScheme, rawURL)
+	}
+
+	if u.Hostname() == "" {
+		return "", nil, errors.New(errHostEmpty)
+	}
+
+	if u.RawQuery != "" || u.ForceQuery || u.Fragment != "" {
+		return "", nil, errors.New(errURLQuery)
+	}
+
+	userinfo := u.User
+	u.User = nil
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+
+	return u.String(), userinfo, nil
+}
+
+// withUserinfo prepends the basic authentication of the url's userinfo to the options,
+// so an authentication option passed by the caller takes precedence.
+func withUserinfo(userinfo *url.Userinfo, options []Option) []Option {
+	if userinfo == nil {
+		return options
+	}
+
+	password, _ := userinfo.Password()
+	return append([]Option{WithBasicAuth(userinfo.Username(), password)}, options...)
+}
+
+// init applies the options and completes the client's setup.
+func (c *Client) init(options []Option) error {
 	for _, opt := range options {
 		opt(c)
 	}
 
+	if c.err != nil {
+		return c.err
+	}
+
 	if c.client == nil {
 		httpClient := &http.Client{}
 		UsingClient(httpClient)(c)
 	}
 
-	return c, nil
+	return c.applyTLS()
+}
+
+// optionErr records the first error of an `Option`, it's returned by the constructor.
+func (c *Client) optionErr(err error) {
+	if c.err == nil {
+		c.err = err
+	}
 }
 
 const (
@@ -189,33 +265,48 @@ func acquireBuffer(b []byte) *bytes.Buffer {
 const schemaAPIVersion = "v1"
 const contentTypeSchemaJSON = "application/vnd.schemaregistry." + schemaAPIVersion + "+json"
 
-func (c *Client) do(method, path, contentType string, send []byte) (*http.Response, error) {
+// do sends the request to the registry, the request is bound to the lifetime of "ctx".
+// Failed requests are retried according to the client's `RetryPolicy`, see `WithRetry`.
+func (c *Client) do(ctx context.Context, method, path, contentType string, send []byte) (*http.Response, error) {
 	if path[0] == '/' {
 		path = path[1:]
 	}
 
-	uri := c.baseURL + "/" + path
+	retryable := c.retry.allows(method, path)
 
-	req, err := http.NewRequest(method, uri, acquireBuffer(send))
-	if err != nil {
-		return nil, err
-	}
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doFailover(ctx, method, path, contentType, send)
+		if err == nil {
+			return resp, nil
+		}
 
-	// set the content type if any.
-	if contentType != "" {
-		req.Header.Set(contentTypeHeaderKey, contentType)
-	}
+		if !retryable || attempt >= c.retry.maxAttempts() || !shouldRetry(ctx, resp, err) {
+			return nil, err
+		}
 
-	// response accept gziped content.
-	req.Header.Add(acceptEncodingHeaderKey, gzipEncodingHeaderValue)
-	req.Header.Add(acceptHeaderKey, contentTypeSchemaJSON+", application/vnd.schemaregistry+json, application/json")
+		if err = sleepContext(ctx, c.retry.backoff(attempt, resp)); err != nil {
+			return nil, err
+		}
+	}
+}
 
-	// send the request and check the response for any connection & authorization errors here.
-	resp, err := c.client.Do(req)
+// doOnce makes a single attempt of the request.
+// On a non-successful status code it returns the, already closed, response along with the error,
+// so the caller can decide if the request is worth to be retried.
+func (c *Client) doOnce(ctx context.Context, method, uri, contentType string, send []byte) (*http.Response, error) {
+	resp, err := c.send(ctx, method, uri, contentType, send)
 	if err != nil {
 		return nil, err
 	}
 
+	// the token may be revoked before its expiry, refresh it and try once more.
+	if resp.StatusCode == http.StatusUnauthorized && c.invalidateToken() {
+		resp.Body.Close()
+		if resp, err = c.send(ctx, method, uri, contentType, send); err != nil {
+			return nil, err
+		}
+	}
+
 	if !isOK(resp) {
 		defer resp.Body.Close()
 		var errBody string
@@ -227,7 +318,7 @@ func (c *Client) do(method, path, contentType string, send []byte) (*http.Respon
 			// if it's json try to read it as confluent's specific error json.
 			var resErr ResourceError
 			c.readJSON(resp, &resErr)
-			return nil, resErr
+			return resp, resErr
 		} else {
 			// else give the whole body to the error context.
 			b, err := c.readResponseBody(resp)
@@ -238,12 +329,36 @@ func (c *Client) do(method, path, contentType string, send []byte) (*http.Respon
 			}
 		}
 
-		return nil, newResourceError(resp.StatusCode, uri, method, errBody)
+		return resp, newResourceError(resp.StatusCode, uri, method, errBody)
 	}
 
 	return resp, nil
 }
 
+// send builds, authenticates and sends the request.
+func (c *Client) send(ctx context.Context, method, uri, contentType string, send []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, uri, acquireBuffer(send))
+	if err != nil {
+		return nil, err
+	}
+
+	// set the content type if any.
+	if contentType != "" {
+		req.Header.Set(contentTypeHeaderKey, contentType)
+	}
+
+	// response accept gziped content.
+	req.Header.Add(acceptEncodingHeaderKey, gzipEncodingHeaderValue)
+	req.Header.Add(acceptHeaderKey, contentTypeSchemaJSON+", application/vnd.schemaregistry+json, application/json")
+
+	if err = c.authenticate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	// send the request and check the response for any connection & authorization errors here.
+	return c.client.Do(req)
+}
+
 type gzipReadCloser struct {
 	respReader io.ReadCloser
 	gzipReader io.ReadCloser
@@ -0,0 +1,60 @@
+package schemaregistry
+
+import (
+	"fmt"
+
+	"github.com/bjornm82/schema-registry/avro"
+)
+
+// CheckAvroCompatibility checks, without a registry, whether an Avro schema is compatible at the given level
+// with the previous schemas of its subject, oldest first. The non-transitive levels check the latest one only.
+// The result has a message per incompatibility, an error is returned when a schema can't be parsed.
+func CheckAvroCompatibility(level CompatibilityLevel, schema string, previous ...string) (CompatibilityResult, error) {
+	s, err := avro.Parse(schema)
+	if err != nil {
+		return CompatibilityResult{}, err
+	}
+
+	var backward, forward bool
+	switch level {
+	case Backward, BackwardTransitive:
+		backward = true
+	case Forward, ForwardTransitive:
+		forward = true
+	case Full, FullTransitive:
+		backward, forward = true, true
+	case None:
+	default:
+		return CompatibilityResult{}, fmt.Errorf("client: unknown compatibility level %d", level)
+	}
+	transitive := level == BackwardTransitive || level == ForwardTransitive || level == FullTransitive
+
+	first := 0
+	if !transitive && len(previous) > 0 {
+		first = len(previous) - 1
+	}
+
+	res := CompatibilityResult{IsCompatible: true}
+	if !backward && !forward {
+		return res, nil
+	}
+	for i := first; i < len(previous); i++ {
+		prev, err := avro.Parse(previous[i])
+		if err != nil {
+			return CompatibilityResult{}, fmt.Errorf("previous schema %d: %v", i+1, err)
+		}
+
+		if backward {
+			for _, inc := range avro.CanRead(s, prev) {
+				res.Messages = append(res.Messages, fmt.Sprintf("the new schema can't read previous schema %d: %s", i+1, inc))
+			}
+		}
+		if forward {
+			for _, inc := range avro.CanRead(prev, s) {
+				res.Messages = append(res.Messages, fmt.Sprintf("previous schema %d can't read the new schema: %s", i+1, inc))
+			}
+		}
+	}
+	res.IsCompatible = len(res.Messages) == 0
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAvroCompatibility(t *testing.T) {
+	v1 := `{"type":"record","name":"User","fields":[{"name":"name","type":"string"}]}`
+	v2 := `{"type":"record","name":"User","fields":[{"name":"name","type":"string"},{"name":"age","type":"int","default":0}]}`
+	// v3 drops the name, which v1 readers need.
+	v3 := `{"type":"record","name":"User","fields":[{"name":"age","type":"int","default":0}]}`
+
+	tests := []struct {
+		level      CompatibilityLevel
+		compatible bool
+	}{
+		{Backward, true},
+		{BackwardTransitive, true},
+		{Forward, false},
+		{ForwardTransitive, false},
+		{Full, false},
+		{None, true},
+	}
+	for _, tt := range tests {
+		res, err := CheckAvroCompatibility(tt.level, v3, v1, v2)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.compatible, res.IsCompatible, tt.level.String())
+	}
+
+	// v4 adds a field without default, the transitive check reports it for every previous schema.
+	v4 := `{"type":"record","name":"User","fields":[{"name":"age","type":"int","default":0},{"name":"email","type":"string"}]}`
+	res, err := CheckAvroCompatibility(BackwardTransitive, v4, v1, v2)
+	assert.NoError(t, err)
+	assert.False(t, res.IsCompatible)
+	assert.Equal(t, []string{
+		`the new schema can't read previous schema 1: /email: field "email" of record User has no default and the writer doesn't have it`,
+		`the new schema can't read previous schema 2: /email: field "email" of record User has no default and the writer doesn't have it`,
+	}, res.Messages)
+
+	res, err = CheckAvroCompatibility(Full, v2)
+	assert.NoError(t, err)
+	assert.True(t, res.IsCompatible)
+
+	_, err = CheckAvroCompatibility(Backward, `{"type":"record"}`)
+	assert.Error(t, err)
+}
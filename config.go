@@ -1,9 +1,11 @@
 package schemaregistry
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -21,7 +23,7 @@ const (
 )
 
 const (
-	configPath          = "/config/%s"
+	configPath          = "config"
 	jsonUnmarhalMessage = "unable to unmarshal json"
 )
 
@@ -47,6 +49,17 @@ func (d CompatibilityLevel) String() string {
 	}
 }
 
+// ParseCompatibilityLevel returns the compatibility level of its string form, e.g. "FULL_TRANSITIVE",
+// the case doesn't matter.
+func ParseCompatibilityLevel(s string) (CompatibilityLevel, error) {
+	for cl := Backward; cl <= None; cl++ {
+		if strings.EqualFold(s, cl.String()) {
+			return cl, nil
+		}
+	}
+	return 0, fmt.Errorf("client: %q is not a valid compatibility level", s)
+}
+
 // Config describes a subject or globa schema-registry configuration
 type Config struct {
 	// CompatibilityLevel mode of subject or global
@@ -54,17 +67,53 @@ type Config struct {
 	CompatibilityLevel string `json:"compatibilityLevel,omitempty"`
 }
 
+// Level returns the compatibility level of the configuration, the registry returns it
+// in the "compatibilityLevel" field on reads and in the "compatibility" one on updates.
+// It fails when the configuration has no level, e.g. a subject which uses the global one.
+func (cfg Config) Level() (CompatibilityLevel, error) {
+	if cfg.CompatibilityLevel != "" {
+		return ParseCompatibilityLevel(cfg.CompatibilityLevel)
+	}
+	return ParseCompatibilityLevel(cfg.Compatibility)
+}
+
 // GetConfig returns the configuration (Config type) for global Schema-Registry or a specific
 // subject. When Config returned has "compatibilityLevel" empty, it's using global settings.
+// The global configuration is returned when subject is empty.
 func (c *Client) GetConfig(subject string) (Config, error) {
-	return c.getConfigSubject(subject)
+	return c.GetConfigContext(context.Background(), subject)
+}
+
+// GetConfigContext same as `GetConfig` but it accepts a context to control the request's lifetime.
+func (c *Client) GetConfigContext(ctx context.Context, subject string) (Config, error) {
+	return c.getConfigSubject(ctx, subject, false)
+}
+
+// GetEffectiveConfig returns the configuration in effect for a subject, which is the global one
+// when the subject has no configuration of its own ("defaultToGlobal").
+func (c *Client) GetEffectiveConfig(subject string) (Config, error) {
+	return c.GetEffectiveConfigContext(context.Background(), subject)
+}
+
+// GetEffectiveConfigContext same as `GetEffectiveConfig` but it accepts a context to control the request's lifetime.
+func (c *Client) GetEffectiveConfigContext(ctx context.Context, subject string) (Config, error) {
+	return c.getConfigSubject(ctx, subject, true)
 }
 
-// SetConfigLevel according to the predefined compatibility levels
+// SetConfigLevel according to the predefined compatibility levels,
+// the global level is set when subject is empty.
 func (c *Client) SetConfigLevel(cl CompatibilityLevel, subject string) (Config, error) {
+	return c.SetConfigLevelContext(context.Background(), cl, subject)
+}
+
+// SetConfigLevelContext same as `SetConfigLevel` but it accepts a context to control the request's lifetime.
+func (c *Client) SetConfigLevelContext(ctx context.Context, cl CompatibilityLevel, subject string) (Config, error) {
 	var config = Config{}
 
-	path := fmt.Sprintf(configPath, subject)
+	if cl.String() == "" {
+		return config, fmt.Errorf("client: %d is not a valid compatibility level", cl)
+	}
+
 	b, err := json.Marshal(Config{
 		Compatibility: cl.String(),
 	})
@@ -72,7 +121,8 @@ func (c *Client) SetConfigLevel(cl CompatibilityLevel, subject string) (Config,
 		return config, errors.Wrap(err, jsonUnmarhalMessage)
 	}
 
-	resp, respErr := c.do(http.MethodPut, path, contentTypeSchemaJSON, b)
+	// PUT /config[/(string: subject)]
+	resp, respErr := c.do(ctx, http.MethodPut, configSubjectPath(subject), contentTypeSchemaJSON, b)
 
 	return c.handle(resp, respErr)
 }
@@ -81,25 +131,71 @@ func (c *Client) SetConfigLevelFull(subject string) (Config, error) {
 	return c.SetConfigLevel(Full, subject)
 }
 
+// SetConfigLevelFullContext same as `SetConfigLevelFull` but it accepts a context to control the request's lifetime.
+func (c *Client) SetConfigLevelFullContext(ctx context.Context, subject string) (Config, error) {
+	return c.SetConfigLevelContext(ctx, Full, subject)
+}
+
+// DeleteConfig deletes the configuration of the subject, which falls back to the global one,
+// or resets the global configuration when subject is empty. Returns the deleted configuration.
+func (c *Client) DeleteConfig(subject string) (Config, error) {
+	return c.DeleteConfigContext(context.Background(), subject)
+}
+
+// DeleteConfigContext same as `DeleteConfig` but it accepts a context to control the request's lifetime.
+func (c *Client) DeleteConfigContext(ctx context.Context, subject string) (Config, error) {
+	// DELETE /config[/(string: subject)]
+	resp, respErr := c.do(ctx, http.MethodDelete, configSubjectPath(subject), "", nil)
+
+	return c.handle(resp, respErr)
+}
+
+func configSubjectPath(subject string) string {
+	if subject == "" {
+		return configPath
+	}
+	return configPath + "/" + subject
+}
+
 // getConfigSubject returns the Config of global or for a given subject. It handles 404 error in a
 // different way, since not-found for a subject configuration means it's using global.
-func (c *Client) getConfigSubject(subject string) (Config, error) {
-	path := fmt.Sprintf(configPath, subject)
-	resp, respErr := c.do(http.MethodGet, path, "", nil)
+func (c *Client) getConfigSubject(ctx context.Context, subject string, defaultToGlobal bool) (Config, error) {
+	// GET /config[/(string: subject)]?defaultToGlobal=(boolean)
+	path := configSubjectPath(subject)
+	if subject != "" && defaultToGlobal {
+		path += "?defaultToGlobal=true"
+	}
+	resp, respErr := c.do(ctx, http.MethodGet, path, "", nil)
+	if subject != "" && isConfigNotFound(respErr) {
+		return Config{}, nil
+	}
 
 	return c.handle(resp, respErr)
 }
 
 func (c *Client) handle(resp *http.Response, respErr error) (Config, error) {
-	var err error
 	var config = Config{}
 
-	if respErr != nil && respErr.(ResourceError).ErrorCode != http.StatusNotFound {
+	if respErr != nil {
 		return config, respErr
 	}
-	if resp != nil {
-		err = c.readJSON(resp, &config)
-	}
 
+	err := c.readJSON(resp, &config)
 	return config, err
 }
+
+// isConfigNotFound reports whether the error tells that a subject has no configuration of its own,
+// older registries answer with a plain not found or with the subject not found code.
+func isConfigNotFound(err error) bool {
+	resErr, ok := err.(ResourceError)
+	if !ok {
+		return false
+	}
+
+	switch resErr.ErrorCode {
+	case http.StatusNotFound, subjectNotFoundCode, subjectCompatibilityNotFoundCode:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,6 +1,8 @@
 package schemaregistry
 
 import (
+	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,3 +31,66 @@ func TestCompatibilityLevelString(t *testing.T) {
 	d := FullTransitive
 	assert.Equal(t, "FULL_TRANSITIVE", d.String())
 }
+
+func TestParseCompatibilityLevel(t *testing.T) {
+	for cl := Backward; cl <= None; cl++ {
+		parsed, err := ParseCompatibilityLevel(cl.String())
+		assert.NoError(t, err)
+		assert.Equal(t, cl, parsed)
+	}
+
+	cl, err := ParseCompatibilityLevel("full_transitive")
+	assert.NoError(t, err)
+	assert.Equal(t, FullTransitive, cl)
+
+	_, err = ParseCompatibilityLevel("sideways")
+	assert.Error(t, err)
+}
+
+func TestConfigLevel(t *testing.T) {
+	cl, err := Config{CompatibilityLevel: "FORWARD"}.Level()
+	assert.NoError(t, err)
+	assert.Equal(t, Forward, cl)
+
+	cl, err = Config{Compatibility: "NONE"}.Level()
+	assert.NoError(t, err)
+	assert.Equal(t, None, cl)
+
+	_, err = Config{}.Level()
+	assert.Error(t, err)
+}
+
+func TestGetConfig_Global(t *testing.T) {
+	c := httpSuccess(t, http.MethodGet, "/config", nil, Config{CompatibilityLevel: "FULL"})
+	cfg, err := c.GetConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, "FULL", cfg.CompatibilityLevel)
+}
+
+func TestGetConfig_SubjectWithoutLevel(t *testing.T) {
+	c := httpError(t, http.StatusNotFound, subjectCompatibilityNotFoundCode, "Subject 'mysubject' does not have subject-level compatibility configured")
+	cfg, err := c.GetConfig("mysubject")
+	assert.NoError(t, err)
+	assert.Equal(t, Config{}, cfg)
+
+	// the global configuration is never missing.
+	_, err = c.GetConfig("")
+	assert.Error(t, err)
+}
+
+func TestGetConfig_ConnectionError(t *testing.T) {
+	c := httpSuccess(t, "", "", nil, nil)
+	c.client = D(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	_, err := c.GetConfig("mysubject")
+	assert.Error(t, err)
+}
+
+func TestDeleteConfig(t *testing.T) {
+	c := httpSuccess(t, http.MethodDelete, "/config/mysubject", nil, Config{CompatibilityLevel: "NONE"})
+	cfg, err := c.DeleteConfig("mysubject")
+	assert.NoError(t, err)
+	assert.Equal(t, "NONE", cfg.CompatibilityLevel)
+}
@@ -0,0 +1,287 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bjornm82/schema-registry/avro"
+)
+
+// ChangeKind is the kind of a `SchemaChange`.
+type ChangeKind string
+
+// The kinds of changes between two schemas.
+const (
+	FieldAdded     ChangeKind = "FIELD_ADDED"
+	FieldRemoved   ChangeKind = "FIELD_REMOVED"
+	TypeChanged    ChangeKind = "TYPE_CHANGED"
+	DefaultChanged ChangeKind = "DEFAULT_CHANGED"
+	SymbolAdded    ChangeKind = "SYMBOL_ADDED"
+	SymbolRemoved  ChangeKind = "SYMBOL_REMOVED"
+)
+
+// SchemaChange is a change between two schemas.
+type SchemaChange struct {
+	Kind ChangeKind `json:"kind"`
+	// Path locates the change by field names, e.g. "/address/city", "items" and "values" stand for
+	// the items of an array and the values of a map, "/" is the root.
+	Path string `json:"path"`
+	// Old and New describe the type, the default or the symbol before and after the change,
+	// Old is empty for an addition and New for a removal.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+func (c SchemaChange) String() string {
+	switch c.Kind {
+	case FieldAdded:
+		return fmt.Sprintf("%s: field added, %s", c.Path, c.New)
+	case FieldRemoved:
+		return fmt.Sprintf("%s: field removed, %s", c.Path, c.Old)
+	case SymbolAdded:
+		return fmt.Sprintf("%s: symbol %s added", c.Path, c.New)
+	case SymbolRemoved:
+		return fmt.Sprintf("%s: symbol %s removed", c.Path, c.Old)
+	case DefaultChanged:
+		return fmt.Sprintf("%s: default changed from %s to %s", c.Path, orNone(c.Old), orNone(c.New))
+	default:
+		return fmt.Sprintf("%s: type changed from %s to %s", c.Path, c.Old, c.New)
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// DiffSchemas returns the structural changes from the old Avro schema to the new one: the added, removed
+// and changed fields of the records, with their types and defaults, and the symbols of the enums.
+// The documentation and the order of the fields are not compared. The schemas with references are
+// compared with `DiffReferencedSchemas`, which fetches the referenced schemas.
+func DiffSchemas(old, new Schema) ([]SchemaChange, error) {
+	if len(old.References) > 0 || len(new.References) > 0 {
+		return nil, fmt.Errorf("client: the schemas with references are compared with DiffReferencedSchemas")
+	}
+	return diffSchemas(old, nil, new, nil)
+}
+
+// DiffReferencedSchemas same as `DiffSchemas` but the schemas may have references, the referenced
+// schemas are fetched from the registry and their changes are part of the result.
+func DiffReferencedSchemas(r Registry, old, new Schema) ([]SchemaChange, error) {
+	return DiffReferencedSchemasContext(context.Background(), r, old, new)
+}
+
+// DiffReferencedSchemasContext same as `DiffReferencedSchemas` but it accepts a context to control the lifetime
+// of the registry requests.
+func DiffReferencedSchemasContext(ctx context.Context, r Registry, old, new Schema) ([]SchemaChange, error) {
+	oldRefs, err := referencedSchemas(ctx, r, old.References)
+	if err != nil {
+		return nil, fmt.Errorf("old schema: %v", err)
+	}
+	newRefs, err := referencedSchemas(ctx, r, new.References)
+	if err != nil {
+		return nil, fmt.Errorf("new schema: %v", err)
+	}
+	return diffSchemas(old, oldRefs, new, newRefs)
+}
+
+// referencedSchemas fetches the schemas the references point to, directly or not, dependencies first.
+func referencedSchemas(ctx context.Context, r Registry, refs []SchemaReference) ([]string, error) {
+	var schemas []string
+	seen := make(map[SchemaReference]bool)
+
+	var visit func(refs []SchemaReference) error
+	visit = func(refs []SchemaReference) error {
+		for _, ref := range refs {
+			key := SchemaReference{Subject: ref.Subject, Version: ref.Version}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			referenced, err := r.GetSchemaBySubjectContext(ctx, ref.Subject, ref.Version)
+			if err != nil {
+				return err
+			}
+			if err := visit(referenced.References); err != nil {
+				return err
+			}
+			schemas = append(schemas, referenced.Schema)
+		}
+		return nil
+	}
+
+	return schemas, visit(refs)
+}
+
+func diffSchemas(old Schema, oldRefs []string, new Schema, newRefs []string) ([]SchemaChange, error) {
+	if old.withDefaultType().SchemaType != Avro || new.withDefaultType().SchemaType != Avro {
+		return nil, fmt.Errorf("client: only avro schemas can be compared")
+	}
+
+	o, err := avro.Parse(old.Schema, oldRefs...)
+	if err != nil {
+		return nil, fmt.Errorf("old schema: %v", err)
+	}
+	n, err := avro.Parse(new.Schema, newRefs...)
+	if err != nil {
+		return nil, fmt.Errorf("new schema: %v", err)
+	}
+
+	d := &differ{seen: make(map[[2]*avro.Schema]bool)}
+	d.diff(o, n, "")
+	return d.changes, nil
+}
+
+type differ struct {
+	// seen are the pairs of named types compared, they stop the recursive types.
+	seen    map[[2]*avro.Schema]bool
+	changes []SchemaChange
+}
+
+func (d *differ) add(kind ChangeKind, path, old, new string) {
+	if path == "" {
+		path = "/"
+	}
+	d.changes = append(d.changes, SchemaChange{Kind: kind, Path: path, Old: old, New: new})
+}
+
+func (d *differ) diff(old, new *avro.Schema, path string) {
+	if old.IsNamed() && new.IsNamed() {
+		pair := [2]*avro.Schema{old, new}
+		if d.seen[pair] {
+			return
+		}
+		d.seen[pair] = true
+	}
+
+	switch {
+	case old.Type == avro.Record && new.Type == avro.Record && old.Name == new.Name:
+		d.diffRecord(old, new, path)
+	case old.Type == avro.Enum && new.Type == avro.Enum && old.Name == new.Name:
+		d.diffEnum(old, new, path)
+	case old.Type == avro.Array && new.Type == avro.Array:
+		d.diff(old.Items, new.Items, path+"/items")
+	case old.Type == avro.Map && new.Type == avro.Map:
+		d.diff(old.Values, new.Values, path+"/values")
+	default:
+		if typeName(old) != typeName(new) {
+			d.add(TypeChanged, path, typeName(old), typeName(new))
+		}
+		if old.Type == avro.Union && new.Type == avro.Union {
+			d.diffBranches(old, new, path)
+		}
+	}
+}
+
+// diffBranches compares the named types found in both unions.
+func (d *differ) diffBranches(old, new *avro.Schema, path string) {
+	for _, ob := range old.Branches {
+		for _, nb := range new.Branches {
+			if ob.IsNamed() && ob.Type == nb.Type && ob.Name == nb.Name {
+				d.diff(ob, nb, path)
+			}
+		}
+	}
+}
+
+func (d *differ) diffRecord(old, new *avro.Schema, path string) {
+	for _, of := range old.Fields {
+		if new.Field(of.Name) == nil {
+			d.add(FieldRemoved, path+"/"+of.Name, typeName(of.Type), "")
+		}
+	}
+
+	for _, nf := range new.Fields {
+		fieldPath := path + "/" + nf.Name
+		of := old.Field(nf.Name)
+		if of == nil {
+			d.add(FieldAdded, fieldPath, "", typeName(nf.Type))
+			continue
+		}
+
+		d.diff(of.Type, nf.Type, fieldPath)
+		// the defaults are compared in their JSON form, the int 0 and the long 0 are the same default.
+		if od, nd := defaultString(of), defaultString(nf); od != nd {
+			d.add(DefaultChanged, fieldPath, od, nd)
+		}
+	}
+}
+
+func (d *differ) diffEnum(old, new *avro.Schema, path string) {
+	for _, sym := range old.Symbols {
+		if !containsString(new.Symbols, sym) {
+			d.add(SymbolRemoved, path, sym, "")
+		}
+	}
+	for _, sym := range new.Symbols {
+		if !containsString(old.Symbols, sym) {
+			d.add(SymbolAdded, path, "", sym)
+		}
+	}
+}
+
+// typeName describes a type without expanding the named types, e.g. "[null, com.example.User]",
+// "array<string>" or "long:timestamp-millis".
+func typeName(s *avro.Schema) string {
+	var name string
+	switch s.Type {
+	case avro.Union:
+		branches := make([]string, 0, len(s.Branches))
+		for _, b := range s.Branches {
+			branches = append(branches, typeName(b))
+		}
+		return "[" + strings.Join(branches, ", ") + "]"
+	case avro.Array:
+		name = "array<" + typeName(s.Items) + ">"
+	case avro.Map:
+		name = "map<" + typeName(s.Values) + ">"
+	case avro.Fixed:
+		name = fmt.Sprintf("%s(%d)", s.Name, s.Size)
+	default:
+		name = s.String()
+	}
+
+	switch s.LogicalType {
+	case "":
+		return name
+	case "decimal":
+		return fmt.Sprintf("%s:decimal(%d,%d)", name, s.Precision, s.Scale)
+	default:
+		return name + ":" + s.LogicalType
+	}
+}
+
+// defaultString returns the JSON form of the default of a field, empty when it has none.
+func defaultString(f *avro.Field) string {
+	if !f.HasDefault {
+		return ""
+	}
+	v := f.Default
+	// bytes are written as strings of code points, as in the schema.
+	if b, ok := v.([]byte); ok {
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		v = string(runes)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package schemaregistry
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	old := Schema{Schema: `{"type": "record", "name": "User", "fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int", "default": 0},
+		{"name": "email", "type": "string"},
+		{"name": "role", "type": {"type": "enum", "name": "Role", "symbols": ["ADMIN", "USER"]}},
+		{"name": "tags", "type": {"type": "array", "items": "string"}}
+	]}`}
+	new := Schema{Schema: `{"type": "record", "name": "User", "doc": "a user", "fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "long", "default": 18},
+		{"name": "role", "type": {"type": "enum", "name": "Role", "symbols": ["USER", "GUEST"]}},
+		{"name": "tags", "type": {"type": "array", "items": "bytes"}},
+		{"name": "phone", "type": ["null", "string"], "default": null}
+	]}`}
+
+	changes, err := DiffSchemas(old, new)
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaChange{
+		{Kind: FieldRemoved, Path: "/email", Old: "string"},
+		{Kind: TypeChanged, Path: "/age", Old: "int", New: "long"},
+		{Kind: DefaultChanged, Path: "/age", Old: "0", New: "18"},
+		{Kind: SymbolRemoved, Path: "/role", Old: "ADMIN"},
+		{Kind: SymbolAdded, Path: "/role", New: "GUEST"},
+		{Kind: TypeChanged, Path: "/tags/items", Old: "string", New: "bytes"},
+		{Kind: FieldAdded, Path: "/phone", New: "[null, string]"},
+	}, changes)
+
+	assert.Equal(t, "/age: default changed from 0 to 18", changes[2].String())
+	assert.Equal(t, "/phone: field added, [null, string]", changes[6].String())
+
+	changes, err = DiffSchemas(old, old)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = DiffSchemas(old, Schema{Schema: `{"type":"object"}`, SchemaType: JSON})
+	assert.Error(t, err)
+}
+
+func TestDiffSchemas_Recursive(t *testing.T) {
+	old := Schema{Schema: `{"type": "record", "name": "Node", "fields": [{"name": "next", "type": ["null", "Node"]}, {"name": "value", "type": "int"}]}`}
+	new := Schema{Schema: `{"type": "record", "name": "Node", "fields": [{"name": "next", "type": ["null", "Node"]}]}`}
+
+	changes, err := DiffSchemas(old, new)
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaChange{{Kind: FieldRemoved, Path: "/value", Old: "int"}}, changes)
+}
+
+func TestDiffReferencedSchemas(t *testing.T) {
+	addresses := map[string]D{
+		"/subjects/address-value/versions/1": dummyHTTPHandler(t, http.MethodGet, "", http.StatusOK, nil,
+			Schema{Schema: `{"type":"record","name":"Address","fields":[{"name":"city","type":"string"}]}`}),
+		"/subjects/address-value/versions/2": dummyHTTPHandler(t, http.MethodGet, "", http.StatusOK, nil,
+			Schema{Schema: `{"type":"record","name":"Address","fields":[{"name":"city","type":"string"},{"name":"zip","type":"string","default":""}]}`}),
+	}
+	c := httpSuccess(t, http.MethodGet, "", nil, nil)
+	c.client = D(func(req *http.Request) (*http.Response, error) {
+		return addresses[req.URL.Path](req)
+	})
+
+	order := `{"type":"record","name":"Order","fields":[{"name":"address","type":"Address"}]}`
+	old := Schema{Schema: order, References: []SchemaReference{{Name: "Address", Subject: "address-value", Version: 1}}}
+	new := Schema{Schema: order, References: []SchemaReference{{Name: "Address", Subject: "address-value", Version: 2}}}
+
+	changes, err := DiffReferencedSchemas(c, old, new)
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaChange{{Kind: FieldAdded, Path: "/address/zip", New: "string"}}, changes)
+
+	_, err = DiffSchemas(old, new)
+	assert.Error(t, err)
+}
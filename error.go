@@ -7,8 +7,11 @@ import (
 
 // These numbers are used by the schema registry to communicate errors.
 const (
-	subjectNotFoundCode = 40401
-	schemaNotFoundCode  = 40403
+	subjectNotFoundCode              = 40401
+	schemaNotFoundCode               = 40403
+	subjectCompatibilityNotFoundCode = 40408
+	subjectModeNotFoundCode          = 40409
+	operationNotPermittedCode        = 42205
 
 	errorMessage    = "client: (%s: %s) failed with error code %d%s"
 	requiredMessage = "client: %s is required"
@@ -0,0 +1,233 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const errNoEndpoints = "at least one registry url is required"
+
+// DefaultEndpointCooldown is the time a registry node is skipped after it failed, see `WithEndpointCooldown`.
+const DefaultEndpointCooldown = 30 * time.Second
+
+// EndpointStatus describes the health of a registry node, look `Client#Endpoints`.
+type EndpointStatus struct {
+	// URL is the base url of the node.
+	URL string
+	// Healthy is false while the node is cooling down after a failure.
+	Healthy bool
+	// Failures is the number of consecutive failed requests.
+	Failures int
+	// LastError is the error of the last failed request, if any.
+	LastError error
+}
+
+type (
+	endpoint struct {
+		url       string
+		failures  int
+		downUntil time.Time
+		lastErr   error
+	}
+
+	// endpointPool keeps track of the registry nodes of a failover client.
+	// Requests stick to the last node that served a request, until it fails.
+	endpointPool struct {
+		mu        sync.Mutex
+		endpoints []*endpoint
+		current   int
+		cooldown  time.Duration
+	}
+)
+
+// order returns the indexes of the endpoints to try, starting from the current one.
+// Healthy endpoints come first, the ones still cooling down are tried as a last resort.
+func (p *endpointPool) order() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	healthy := make([]int, 0, len(p.endpoints))
+	var down []int
+	for i := range p.endpoints {
+		idx := (p.current + i) % len(p.endpoints)
+		if now.Before(p.endpoints[idx].downUntil) {
+			down = append(down, idx)
+			continue
+		}
+		healthy = append(healthy, idx)
+	}
+
+	return append(healthy, down...)
+}
+
+func (p *endpointPool) url(idx int) string {
+	return p.endpoints[idx].url
+}
+
+func (p *endpointPool) markSuccess(idx int) {
+	p.mu.Lock()
+	e := p.endpoints[idx]
+	e.failures = 0
+	e.downUntil = time.Time{}
+	e.lastErr = nil
+	p.current = idx
+	p.mu.Unlock()
+}
+
+func (p *endpointPool) markFailure(idx int, err error) {
+	p.mu.Lock()
+	e := p.endpoints[idx]
+	e.failures++
+	e.downUntil = time.Now().Add(p.cooldown)
+	e.lastErr = err
+	if p.current == idx {
+		p.current = (idx + 1) % len(p.endpoints)
+	}
+	p.mu.Unlock()
+}
+
+func (p *endpointPool) status() []EndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		statuses = append(statuses, EndpointStatus{
+			URL:       e.url,
+			Healthy:   !now.Before(e.downUntil),
+			Failures:  e.failures,
+			LastError: e.lastErr,
+		})
+	}
+
+	return statuses
+}
+
+// NewFailoverClient creates & returns a new Registry Schema Client which is served by several registry nodes.
+// Each url may also be a comma-separated list of urls, like the ones accepted by the Confluent clients.
+// Urls are validated like the one of `NewClientFromURL`.
+//
+// Requests are sent to one node at a time, on a connection error or a 5xx response
+// the node is marked as unhealthy and the request is sent to the next one.
+// See `WithEndpointCooldown`, `Client#Endpoints` and `ContextWithServedBy` too.
+func NewFailoverClient(urls []string, options ...Option) (*Client, error) {
+	var (
+		endpoints []*endpoint
+		userinfo  *url.Userinfo
+	)
+	for _, rawURLs := range urls {
+		for _, rawURL := range strings.Split(rawURLs, ",") {
+			if strings.TrimSpace(rawURL) == "" {
+				continue
+			}
+
+			baseURL, ui, err := parseBaseURL(rawURL)
+			if err != nil {
+				return nil, err
+			}
+			if userinfo == nil {
+				userinfo = ui
+			}
+
+			endpoints = append(endpoints, &endpoint{url: baseURL})
+		}
+	}
+
+	if len(endpoints) == 0 {
+		return nil, errors.New(errNoEndpoints)
+	}
+
+	c := &Client{
+		baseURL:   endpoints[0].url,
+		endpoints: &endpointPool{endpoints: endpoints, cooldown: DefaultEndpointCooldown},
+	}
+	if err := c.init(withUserinfo(userinfo, options)); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// WithEndpointCooldown sets the time a failed registry node is skipped by a failover client, see `NewFailoverClient`.
+func WithEndpointCooldown(d time.Duration) Option {
+	return func(c *Client) {
+		if c.endpoints != nil {
+			c.endpoints.cooldown = d
+		}
+	}
+}
+
+// Endpoints returns the registry nodes of the client and their health.
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.endpoints == nil {
+		return []EndpointStatus{{URL: c.baseURL, Healthy: true}}
+	}
+
+	return c.endpoints.status()
+}
+
+type servedByContextKey struct{}
+
+// ContextWithServedBy returns a copy of "ctx" which records the base url of the registry node
+// that served a request into "endpoint". Use it with the `Context` variants of the client's methods.
+func ContextWithServedBy(ctx context.Context, endpoint *string) context.Context {
+	return context.WithValue(ctx, servedByContextKey{}, endpoint)
+}
+
+func reportServedBy(ctx context.Context, endpoint string) {
+	if ptr, ok := ctx.Value(servedByContextKey{}).(*string); ok && ptr != nil {
+		*ptr = endpoint
+	}
+}
+
+// doFailover makes a single attempt of the request,
+// a failover client sends it to the next node when the current one fails.
+func (c *Client) doFailover(ctx context.Context, method, path, contentType string, send []byte) (*http.Response, error) {
+	if c.endpoints == nil {
+		resp, err := c.doOnce(ctx, method, c.baseURL+"/"+path, contentType, send)
+		if resp != nil {
+			reportServedBy(ctx, c.baseURL)
+		}
+		return resp, err
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	for _, idx := range c.endpoints.order() {
+		baseURL := c.endpoints.url(idx)
+		resp, err = c.doOnce(ctx, method, baseURL+"/"+path, contentType, send)
+		if err != nil && isNodeFailure(ctx, resp, err) {
+			c.endpoints.markFailure(idx, err)
+			continue
+		}
+
+		c.endpoints.markSuccess(idx)
+		reportServedBy(ctx, baseURL)
+		return resp, err
+	}
+
+	return resp, err
+}
+
+// isNodeFailure reports whether the failed request should be sent to another registry node.
+func isNodeFailure(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if resp != nil {
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+
+	return isConnectionError(err)
+}
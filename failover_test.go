@@ -0,0 +1,122 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nodesHTTPHandler answers with the status registered for the requested host, hosts not found are unreachable.
+func nodesHTTPHandler(t *testing.T, statuses map[string]int, respBody interface{}) (D, *[]string) {
+	var hosts []string
+	d := D(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		status, ok := statuses[req.URL.Host]
+		if !ok {
+			return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: assert.AnError}
+		}
+
+		body := respBody
+		if status != http.StatusOK {
+			body = ResourceError{ErrorCode: status}
+		}
+		bs, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{contentTypeHeaderKey: []string{contentTypeJSON}},
+			Body:       ioutil.NopCloser(bytes.NewReader(bs)),
+		}, nil
+	})
+	return d, &hosts
+}
+
+func TestNewFailoverClient(t *testing.T) {
+	c, err := NewFailoverClient([]string{"http://node1:8081,http://node2:8081/", " https://node3 "})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://node1:8081", c.baseURL)
+	assert.Equal(t, []EndpointStatus{
+		{URL: "http://node1:8081", Healthy: true},
+		{URL: "http://node2:8081", Healthy: true},
+		{URL: "https://node3", Healthy: true},
+	}, c.Endpoints())
+}
+
+func TestNewFailoverClient_Failed(t *testing.T) {
+	_, err := NewFailoverClient(nil)
+	assert.EqualError(t, err, errNoEndpoints)
+
+	_, err = NewFailoverClient([]string{"node1:8081"})
+	assert.Error(t, err)
+
+	_, err = NewFailoverClient([]string{"http://"})
+	assert.EqualError(t, err, errHostEmpty)
+}
+
+func TestFailover_RotatesOnFailure(t *testing.T) {
+	d, hosts := nodesHTTPHandler(t, map[string]int{
+		"node2": http.StatusServiceUnavailable,
+		"node3": http.StatusOK,
+	}, []string{"subject"})
+	c, err := NewFailoverClient([]string{"http://node1,http://node2,http://node3"}, UsingClient(nil))
+	assert.NoError(t, err)
+	c.client = d
+
+	var servedBy string
+	subs, err := c.SubjectsContext(ContextWithServedBy(context.Background(), &servedBy))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"subject"}, subs)
+	assert.Equal(t, "http://node3", servedBy)
+	assert.Equal(t, []string{"node1", "node2", "node3"}, *hosts)
+
+	statuses := c.Endpoints()
+	assert.False(t, statuses[0].Healthy)
+	assert.Equal(t, 1, statuses[0].Failures)
+	assert.Error(t, statuses[0].LastError)
+	assert.False(t, statuses[1].Healthy)
+	assert.True(t, statuses[2].Healthy)
+
+	// the next request sticks to the node that served the last one.
+	*hosts = nil
+	_, err = c.Subjects()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node3"}, *hosts)
+}
+
+func TestFailover_NotRotatedOnClientErrors(t *testing.T) {
+	d, hosts := nodesHTTPHandler(t, map[string]int{
+		"node1": http.StatusNotFound,
+		"node2": http.StatusOK,
+	}, []int{1})
+	c, err := NewFailoverClient([]string{"http://node1", "http://node2"})
+	assert.NoError(t, err)
+	c.client = d
+
+	_, err = c.Versions("subject")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"node1"}, *hosts)
+	assert.True(t, c.Endpoints()[0].Healthy)
+}
+
+func TestFailover_AllNodesDown(t *testing.T) {
+	d, hosts := nodesHTTPHandler(t, map[string]int{}, nil)
+	c, err := NewFailoverClient([]string{"http://node1", "http://node2"}, WithEndpointCooldown(0))
+	assert.NoError(t, err)
+	c.client = d
+
+	_, err = c.Subjects()
+	assert.Error(t, err)
+	assert.Equal(t, []string{"node1", "node2"}, *hosts)
+	for _, status := range c.Endpoints() {
+		assert.True(t, status.Healthy)
+		assert.Equal(t, 1, status.Failures)
+	}
+}
@@ -0,0 +1,170 @@
+package schemaregistry
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// lruCache is a size-bounded, least recently used, cache which is safe for concurrent use.
+// A size less or equal to zero means that the cache is unbounded.
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[interface{}]*list.Element
+}
+
+type lruEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[interface{}]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true
+}
+
+func (c *lruCache) add(key, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry).value = value
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value})
+	if c.size > 0 && c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) remove(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// removeIf removes the entries whose key matches.
+func (c *lruCache) removeIf(match func(key interface{}) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.items {
+		if match(key) {
+			c.ll.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
+
+func (c *lruCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *lruCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[interface{}]*list.Element)
+}
+
+// flightGroup de-duplicates concurrent calls with the same key,
+// the callers which arrive while a call is in flight share its result.
+type flightGroup struct {
+	// timeout bounds a call, which doesn't end with the callers' contexts, zero means no limit.
+	timeout time.Duration
+
+	mu    sync.Mutex
+	calls map[interface{}]*flightCall
+}
+
+type flightCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+	// panicked is the value of a panic of the call, it's raised again in every caller.
+	panicked interface{}
+}
+
+// do runs fn once for the concurrent calls with the same key. The call runs on a context which keeps the values
+// of the first caller's one but not its deadline and cancellation, so a caller which gives up doesn't fail the
+// others: every caller waits for the shared result until its own context is done. The call has its own
+// timeout instead, so a hanging registry doesn't hold the key forever.
+func (g *flightGroup) do(ctx context.Context, key interface{}, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[interface{}]*flightCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(detachedContext{ctx}, key, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.panicked != nil {
+			panic(call.panicked)
+		}
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key interface{}, call *flightCall, fn func(ctx context.Context) (interface{}, error)) {
+	defer func() {
+		call.panicked = recover()
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+	call.value, call.err = fn(ctx)
+}
+
+// detachedContext keeps the values of a context but not its deadline and cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
@@ -0,0 +1,1113 @@
+// Package mock provides an in-memory implementation of the schemaregistry.Registry interface,
+// it records every call and it can be programmed to fail, so code which depends on
+// the registry can be unit tested without a running schema registry.
+package mock
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+)
+
+// These numbers are used by the schema registry to communicate errors.
+const (
+	subjectNotFoundCode              = 40401
+	versionNotFoundCode              = 40402
+	schemaNotFoundCode               = 40403
+	subjectSoftDeletedCode           = 40404
+	subjectNotSoftDeletedCode        = 40405
+	versionSoftDeletedCode           = 40406
+	versionNotSoftDeletedCode        = 40407
+	subjectCompatibilityNotFoundCode = 40408
+	subjectModeNotFoundCode          = 40409
+	operationNotPermittedCode        = 42205
+)
+
+// Call is a recorded call of a `Registry` method.
+// The `Context` variants of the methods are recorded under the name of the method without the suffix.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Registry is an in-memory `schemaregistry.Registry`. It's safe for concurrent use.
+type Registry struct {
+	// CompatibilityFunc, if set, decides whether a schema is compatible with a registered one,
+	// by default every schema is compatible.
+	CompatibilityFunc func(subject string, schema, registered schemaregistry.Schema) bool
+
+	mu       sync.Mutex
+	subjects map[string][]schemaregistry.Schema
+	deleted  map[subjectVersion]bool
+	ids      map[schemaKey]int
+	schemas  map[int]schemaregistry.Schema
+	configs  map[string]schemaregistry.CompatibilityLevel
+	modes    map[string]schemaregistry.Mode
+	nextID   int
+	calls    []Call
+	errs     map[string]error
+}
+
+var _ schemaregistry.Registry = (*Registry)(nil)
+
+// subjectVersion identifies a soft-deleted version.
+type subjectVersion struct {
+	subject string
+	version int
+}
+
+// schemaKey identifies a schema across subjects, the same schema always gets the same ID.
+type schemaKey struct {
+	schema     string
+	schemaType schemaregistry.SchemaType
+	references string
+}
+
+func newSchemaKey(s schemaregistry.Schema) schemaKey {
+	if s.SchemaType == "" {
+		s.SchemaType = schemaregistry.Avro
+	}
+	var refs strings.Builder
+	for _, ref := range s.References {
+		fmt.Fprintf(&refs, "%s=%s:%d;", ref.Name, ref.Subject, ref.Version)
+	}
+	return schemaKey{schema: s.Schema, schemaType: s.SchemaType, references: refs.String()}
+}
+
+// New returns an empty in-memory registry, its global compatibility level is BACKWARD.
+func New() *Registry {
+	return &Registry{
+		subjects: make(map[string][]schemaregistry.Schema),
+		deleted:  make(map[subjectVersion]bool),
+		ids:      make(map[schemaKey]int),
+		schemas:  make(map[int]schemaregistry.Schema),
+		configs:  map[string]schemaregistry.CompatibilityLevel{"": schemaregistry.Backward},
+		modes:    map[string]schemaregistry.Mode{"": schemaregistry.ReadWrite},
+		errs:     make(map[string]error),
+	}
+}
+
+// SetError makes every following call of "method" return "err", a nil error clears it.
+// The method name is the one without the `Context` suffix, e.g. "RegisterNewSchema".
+func (r *Registry) SetError(method string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err == nil {
+		delete(r.errs, method)
+		return
+	}
+	r.errs[method] = err
+}
+
+// Calls returns the recorded calls, in order.
+func (r *Registry) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsOf returns the recorded calls of "method".
+func (r *Registry) CallsOf(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var calls []Call
+	for _, c := range r.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// ResetCalls drops the recorded calls.
+func (r *Registry) ResetCalls() {
+	r.mu.Lock()
+	r.calls = nil
+	r.mu.Unlock()
+}
+
+// record must be called with the lock held, it returns the programmed error of the method, if any.
+func (r *Registry) record(ctx context.Context, method string, args ...interface{}) error {
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.errs[method]
+}
+
+func notFound(code int, format string, args ...interface{}) error {
+	return schemaregistry.ResourceError{
+		ErrorCode: code,
+		Method:    http.MethodGet,
+		Message:   fmt.Sprintf(format, args...),
+	}
+}
+
+// live returns the versions of the subject, the soft-deleted ones are included only if "deleted" is true.
+func (r *Registry) live(subject string, deleted bool) []schemaregistry.Schema {
+	var versions []schemaregistry.Schema
+	for _, s := range r.subjects[subject] {
+		if deleted || !r.deleted[subjectVersion{subject, s.Version}] {
+			versions = append(versions, s)
+		}
+	}
+	return versions
+}
+
+func notPermitted(format string, args ...interface{}) error {
+	return schemaregistry.ResourceError{
+		ErrorCode: operationNotPermittedCode,
+		Method:    http.MethodPost,
+		Message:   fmt.Sprintf(format, args...),
+	}
+}
+
+func required(field string) error {
+	return fmt.Errorf("client: %s is required", field)
+}
+
+// RegisterNewSchema registers a schema under the subject, the same schema always gets the same ID.
+func (r *Registry) RegisterNewSchema(subject string, avroSchema string) (int, error) {
+	return r.RegisterNewSchemaContext(context.Background(), subject, avroSchema)
+}
+
+// RegisterNewSchemaContext same as `RegisterNewSchema` but it accepts a context.
+func (r *Registry) RegisterNewSchemaContext(ctx context.Context, subject string, avroSchema string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "RegisterNewSchema", subject, avroSchema); err != nil {
+		return 0, err
+	}
+	if avroSchema == "" {
+		return 0, required("avroSchema")
+	}
+	return r.register(subject, schemaregistry.Schema{Schema: avroSchema}, false)
+}
+
+// RegisterSchema registers a schema of any type under the subject.
+func (r *Registry) RegisterSchema(subject string, schema schemaregistry.Schema) (int, error) {
+	return r.RegisterSchemaContext(context.Background(), subject, schema)
+}
+
+// RegisterSchemaContext same as `RegisterSchema` but it accepts a context.
+func (r *Registry) RegisterSchemaContext(ctx context.Context, subject string, schema schemaregistry.Schema) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "RegisterSchema", subject, schema); err != nil {
+		return 0, err
+	}
+	return r.register(subject, schema, false)
+}
+
+// ImportSchema registers a schema with its ID and, when not zero, its version, the subject must be in IMPORT mode.
+func (r *Registry) ImportSchema(subject string, schema schemaregistry.Schema) (int, error) {
+	return r.ImportSchemaContext(context.Background(), subject, schema)
+}
+
+// ImportSchemaContext same as `ImportSchema` but it accepts a context.
+func (r *Registry) ImportSchemaContext(ctx context.Context, subject string, schema schemaregistry.Schema) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "ImportSchema", subject, schema); err != nil {
+		return 0, err
+	}
+	if schema.ID <= 0 {
+		return 0, required("schema ID")
+	}
+	return r.register(subject, schema, true)
+}
+
+// register registers the schema under the subject, an imported schema keeps its ID and version.
+func (r *Registry) register(subject string, schema schemaregistry.Schema, imported bool) (int, error) {
+	if subject == "" {
+		return 0, required("subject")
+	}
+	if schema.Schema == "" {
+		return 0, required("schema")
+	}
+
+	switch mode := r.mode(subject); {
+	case mode == schemaregistry.ReadOnly:
+		return 0, notPermitted("Subject %s is in read-only mode", subject)
+	case imported && mode != schemaregistry.Import:
+		return 0, notPermitted("Subject %s is not in import mode", subject)
+	}
+
+	key := newSchemaKey(schema)
+	for _, s := range r.live(subject, false) {
+		if newSchemaKey(s) == key && (!imported || s.ID == schema.ID) {
+			return s.ID, nil
+		}
+	}
+
+	for _, ref := range schema.References {
+		if _, err := r.schemaAt(ref.Subject, ref.Version); err != nil {
+			return 0, err
+		}
+	}
+
+	id, ok := r.ids[key]
+	if imported {
+		if existing, taken := r.schemas[schema.ID]; taken && newSchemaKey(existing) != key {
+			return 0, notPermitted("Overwrite new schema with id %d is not permitted.", schema.ID)
+		}
+		id, ok = schema.ID, false
+	}
+	if !ok {
+		if !imported {
+			r.nextID++
+			for _, taken := r.schemas[r.nextID]; taken; _, taken = r.schemas[r.nextID] {
+				r.nextID++
+			}
+			id = r.nextID
+		}
+		if _, known := r.ids[key]; !known {
+			r.ids[key] = id
+		}
+		r.schemas[id] = schemaregistry.Schema{Schema: key.schema, ID: id, SchemaType: key.schemaType, References: schema.References}
+	}
+
+	versions := r.subjects[subject]
+	version := 1
+	if len(versions) > 0 {
+		version = versions[len(versions)-1].Version + 1
+	}
+	if imported && schema.Version > 0 {
+		for _, s := range versions {
+			if s.Version == schema.Version {
+				return 0, notPermitted("Version %d of subject %s already exists", schema.Version, subject)
+			}
+		}
+		if schema.Version < version {
+			return 0, notPermitted("Version %d of subject %s is lower than the latest one", schema.Version, subject)
+		}
+		version = schema.Version
+	}
+	r.subjects[subject] = append(versions, schemaregistry.Schema{
+		Schema:     key.schema,
+		Subject:    subject,
+		Version:    version,
+		ID:         id,
+		SchemaType: key.schemaType,
+		References: schema.References,
+	})
+
+	return id, nil
+}
+
+// GetSchemaByID returns the schema string identified by the id.
+func (r *Registry) GetSchemaByID(subjectID int) (string, error) {
+	return r.GetSchemaByIDContext(context.Background(), subjectID)
+}
+
+// GetSchemaByIDContext same as `GetSchemaByID` but it accepts a context.
+func (r *Registry) GetSchemaByIDContext(ctx context.Context, subjectID int) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetSchemaByID", subjectID); err != nil {
+		return "", err
+	}
+
+	s, err := r.schemaByID(subjectID)
+	return s.Schema, err
+}
+
+// GetSchemaDetailsByID returns the schema identified by the id along with its type.
+func (r *Registry) GetSchemaDetailsByID(subjectID int) (schemaregistry.Schema, error) {
+	return r.GetSchemaDetailsByIDContext(context.Background(), subjectID)
+}
+
+// GetSchemaDetailsByIDContext same as `GetSchemaDetailsByID` but it accepts a context.
+func (r *Registry) GetSchemaDetailsByIDContext(ctx context.Context, subjectID int) (schemaregistry.Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetSchemaDetailsByID", subjectID); err != nil {
+		return schemaregistry.Schema{}, err
+	}
+	return r.schemaByID(subjectID)
+}
+
+func (r *Registry) schemaByID(id int) (schemaregistry.Schema, error) {
+	s, ok := r.schemas[id]
+	if !ok {
+		return schemaregistry.Schema{}, notFound(schemaNotFoundCode, "Schema %d not found", id)
+	}
+	return s, nil
+}
+
+// SchemaTypes returns the schema types supported by the registry.
+func (r *Registry) SchemaTypes() ([]schemaregistry.SchemaType, error) {
+	return r.SchemaTypesContext(context.Background())
+}
+
+// SchemaTypesContext same as `SchemaTypes` but it accepts a context.
+func (r *Registry) SchemaTypesContext(ctx context.Context) ([]schemaregistry.SchemaType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "SchemaTypes"); err != nil {
+		return nil, err
+	}
+	return []schemaregistry.SchemaType{schemaregistry.JSON, schemaregistry.Protobuf, schemaregistry.Avro}, nil
+}
+
+// GetSchemaBySubject returns the schema for a particular subject and version.
+func (r *Registry) GetSchemaBySubject(subject string, versionID int) (schemaregistry.Schema, error) {
+	return r.GetSchemaBySubjectContext(context.Background(), subject, versionID)
+}
+
+// GetSchemaBySubjectContext same as `GetSchemaBySubject` but it accepts a context.
+func (r *Registry) GetSchemaBySubjectContext(ctx context.Context, subject string, versionID int) (schemaregistry.Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetSchemaBySubject", subject, versionID); err != nil {
+		return schemaregistry.Schema{}, err
+	}
+	return r.schemaAt(subject, versionID)
+}
+
+// GetLatestSchema returns the latest version of a schema.
+func (r *Registry) GetLatestSchema(subject string) (schemaregistry.Schema, error) {
+	return r.GetLatestSchemaContext(context.Background(), subject)
+}
+
+// GetLatestSchemaContext same as `GetLatestSchema` but it accepts a context.
+func (r *Registry) GetLatestSchemaContext(ctx context.Context, subject string) (schemaregistry.Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetLatestSchema", subject); err != nil {
+		return schemaregistry.Schema{}, err
+	}
+	return r.schemaAt(subject, -1)
+}
+
+// schemaAt returns the schema of the subject at the given version, -1 is the latest one.
+func (r *Registry) schemaAt(subject string, version int) (schemaregistry.Schema, error) {
+	versions := r.live(subject, false)
+	if len(versions) == 0 {
+		return schemaregistry.Schema{}, notFound(subjectNotFoundCode, "Subject '%s' not found.", subject)
+	}
+
+	if version == -1 {
+		return versions[len(versions)-1], nil
+	}
+
+	for _, s := range versions {
+		if s.Version == version {
+			return s, nil
+		}
+	}
+	return schemaregistry.Schema{}, notFound(versionNotFoundCode, "Version %d not found.", version)
+}
+
+// ReferencedBy returns the IDs of the schemas which refer to the schema of the subject at the given version.
+func (r *Registry) ReferencedBy(subject string, versionID int) ([]int, error) {
+	return r.ReferencedByContext(context.Background(), subject, versionID)
+}
+
+// ReferencedByContext same as `ReferencedBy` but it accepts a context.
+func (r *Registry) ReferencedByContext(ctx context.Context, subject string, versionID int) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "ReferencedBy", subject, versionID); err != nil {
+		return nil, err
+	}
+	if _, err := r.schemaAt(subject, versionID); err != nil {
+		return nil, err
+	}
+
+	ids := []int{}
+	for id := 1; id <= r.nextID; id++ {
+		for _, ref := range r.schemas[id].References {
+			if ref.Subject == subject && ref.Version == versionID {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+// IsRegistered tells if the given "schema" is registered for this "subject".
+func (r *Registry) IsRegistered(subject, schema string) (bool, schemaregistry.Schema, error) {
+	return r.IsRegisteredContext(context.Background(), subject, schema)
+}
+
+// IsRegisteredContext same as `IsRegistered` but it accepts a context.
+func (r *Registry) IsRegisteredContext(ctx context.Context, subject, schema string) (bool, schemaregistry.Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "IsRegistered", subject, schema); err != nil {
+		return false, schemaregistry.Schema{}, err
+	}
+	return r.lookup(subject, schemaregistry.Schema{Schema: schema})
+}
+
+// LookupSchema tells if the given schema, of any type, is registered for this "subject".
+func (r *Registry) LookupSchema(subject string, schema schemaregistry.Schema) (bool, schemaregistry.Schema, error) {
+	return r.LookupSchemaContext(context.Background(), subject, schema)
+}
+
+// LookupSchemaContext same as `LookupSchema` but it accepts a context.
+func (r *Registry) LookupSchemaContext(ctx context.Context, subject string, schema schemaregistry.Schema) (bool, schemaregistry.Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "LookupSchema", subject, schema); err != nil {
+		return false, schemaregistry.Schema{}, err
+	}
+	return r.lookup(subject, schema)
+}
+
+func (r *Registry) lookup(subject string, schema schemaregistry.Schema) (bool, schemaregistry.Schema, error) {
+	versions := r.live(subject, false)
+	if len(versions) == 0 {
+		return false, schemaregistry.Schema{}, notFound(subjectNotFoundCode, "Subject '%s' not found.", subject)
+	}
+
+	key := newSchemaKey(schema)
+	for _, s := range versions {
+		if newSchemaKey(s) == key {
+			return true, s, nil
+		}
+	}
+	return false, schemaregistry.Schema{}, nil
+}
+
+// IsSchemaCompatible tests compatibility with a specific version of a subject's schema, see `CompatibilityFunc`.
+func (r *Registry) IsSchemaCompatible(subject string, avroSchema string, versionID int) (bool, error) {
+	return r.IsSchemaCompatibleContext(context.Background(), subject, avroSchema, versionID)
+}
+
+// IsSchemaCompatibleContext same as `IsSchemaCompatible` but it accepts a context.
+func (r *Registry) IsSchemaCompatibleContext(ctx context.Context, subject string, avroSchema string, versionID int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "IsSchemaCompatible", subject, avroSchema, versionID); err != nil {
+		return false, err
+	}
+	return r.isCompatible(subject, schemaregistry.Schema{Schema: avroSchema}, versionID)
+}
+
+// IsLatestSchemaCompatible tests compatibility with the latest version of a subject's schema, see `CompatibilityFunc`.
+func (r *Registry) IsLatestSchemaCompatible(subject string, avroSchema string) (bool, error) {
+	return r.IsLatestSchemaCompatibleContext(context.Background(), subject, avroSchema)
+}
+
+// IsLatestSchemaCompatibleContext same as `IsLatestSchemaCompatible` but it accepts a context.
+func (r *Registry) IsLatestSchemaCompatibleContext(ctx context.Context, subject string, avroSchema string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "IsLatestSchemaCompatible", subject, avroSchema); err != nil {
+		return false, err
+	}
+	return r.isCompatible(subject, schemaregistry.Schema{Schema: avroSchema}, -1)
+}
+
+// CheckCompatibility tests compatibility of a schema, of any type, with a specific version of a subject's schema.
+func (r *Registry) CheckCompatibility(subject string, schema schemaregistry.Schema, versionID int) (bool, error) {
+	return r.CheckCompatibilityContext(context.Background(), subject, schema, versionID)
+}
+
+// CheckCompatibilityContext same as `CheckCompatibility` but it accepts a context.
+func (r *Registry) CheckCompatibilityContext(ctx context.Context, subject string, schema schemaregistry.Schema, versionID int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "CheckCompatibility", subject, schema, versionID); err != nil {
+		return false, err
+	}
+	return r.isCompatible(subject, schema, versionID)
+}
+
+// CheckLatestCompatibility tests compatibility of a schema, of any type, with the latest version of a subject's schema.
+func (r *Registry) CheckLatestCompatibility(subject string, schema schemaregistry.Schema) (bool, error) {
+	return r.CheckLatestCompatibilityContext(context.Background(), subject, schema)
+}
+
+// CheckLatestCompatibilityContext same as `CheckLatestCompatibility` but it accepts a context.
+func (r *Registry) CheckLatestCompatibilityContext(ctx context.Context, subject string, schema schemaregistry.Schema) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "CheckLatestCompatibility", subject, schema); err != nil {
+		return false, err
+	}
+	return r.isCompatible(subject, schema, -1)
+}
+
+// CheckCompatibilityVerbose tests compatibility with a specific version of a subject's schema, see `CompatibilityFunc`.
+func (r *Registry) CheckCompatibilityVerbose(subject string, schema schemaregistry.Schema, versionID int) (schemaregistry.CompatibilityResult, error) {
+	return r.CheckCompatibilityVerboseContext(context.Background(), subject, schema, versionID)
+}
+
+// CheckCompatibilityVerboseContext same as `CheckCompatibilityVerbose` but it accepts a context.
+func (r *Registry) CheckCompatibilityVerboseContext(ctx context.Context, subject string, schema schemaregistry.Schema, versionID int) (schemaregistry.CompatibilityResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "CheckCompatibilityVerbose", subject, schema, versionID); err != nil {
+		return schemaregistry.CompatibilityResult{}, err
+	}
+	registered, err := r.schemaAt(subject, versionID)
+	if err != nil {
+		return schemaregistry.CompatibilityResult{}, err
+	}
+	return r.compatibilityResult(subject, schema, registered), nil
+}
+
+// CheckLatestCompatibilityVerbose tests compatibility with the latest version of a subject's schema, see `CompatibilityFunc`.
+func (r *Registry) CheckLatestCompatibilityVerbose(subject string, schema schemaregistry.Schema) (schemaregistry.CompatibilityResult, error) {
+	return r.CheckLatestCompatibilityVerboseContext(context.Background(), subject, schema)
+}
+
+// CheckLatestCompatibilityVerboseContext same as `CheckLatestCompatibilityVerbose` but it accepts a context.
+func (r *Registry) CheckLatestCompatibilityVerboseContext(ctx context.Context, subject string, schema schemaregistry.Schema) (schemaregistry.CompatibilityResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "CheckLatestCompatibilityVerbose", subject, schema); err != nil {
+		return schemaregistry.CompatibilityResult{}, err
+	}
+	registered, err := r.schemaAt(subject, -1)
+	if err != nil {
+		return schemaregistry.CompatibilityResult{}, err
+	}
+	return r.compatibilityResult(subject, schema, registered), nil
+}
+
+// CheckAllVersionsCompatibility tests compatibility with the versions of the subject its compatibility level requires,
+// all of them for the transitive levels and the latest one otherwise, see `CompatibilityFunc`.
+func (r *Registry) CheckAllVersionsCompatibility(subject string, schema schemaregistry.Schema) (schemaregistry.CompatibilityResult, error) {
+	return r.CheckAllVersionsCompatibilityContext(context.Background(), subject, schema)
+}
+
+// CheckAllVersionsCompatibilityContext same as `CheckAllVersionsCompatibility` but it accepts a context.
+func (r *Registry) CheckAllVersionsCompatibilityContext(ctx context.Context, subject string, schema schemaregistry.Schema) (schemaregistry.CompatibilityResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "CheckAllVersionsCompatibility", subject, schema); err != nil {
+		return schemaregistry.CompatibilityResult{}, err
+	}
+
+	versions := r.live(subject, false)
+	if len(versions) == 0 {
+		return schemaregistry.CompatibilityResult{}, notFound(subjectNotFoundCode, "Subject '%s' not found.", subject)
+	}
+	level, ok := r.configs[subject]
+	if !ok {
+		level = r.configs[""]
+	}
+	switch level {
+	case schemaregistry.BackwardTransitive, schemaregistry.ForwardTransitive, schemaregistry.FullTransitive:
+	default:
+		versions = versions[len(versions)-1:]
+	}
+
+	return r.compatibilityResult(subject, schema, versions...), nil
+}
+
+// compatibilityResult checks the schema against the registered ones, one message per incompatible version.
+func (r *Registry) compatibilityResult(subject string, schema schemaregistry.Schema, registered ...schemaregistry.Schema) schemaregistry.CompatibilityResult {
+	res := schemaregistry.CompatibilityResult{IsCompatible: true}
+	if r.CompatibilityFunc == nil {
+		return res
+	}
+
+	for _, s := range registered {
+		if !r.CompatibilityFunc(subject, schema, s) {
+			res.IsCompatible = false
+			res.Messages = append(res.Messages, fmt.Sprintf("the schema is incompatible with version %d of subject %s", s.Version, subject))
+		}
+	}
+	return res
+}
+
+func (r *Registry) isCompatible(subject string, schema schemaregistry.Schema, version int) (bool, error) {
+	registered, err := r.schemaAt(subject, version)
+	if err != nil {
+		return false, err
+	}
+	if r.CompatibilityFunc == nil {
+		return true, nil
+	}
+	return r.CompatibilityFunc(subject, schema, registered), nil
+}
+
+// Subjects returns a sorted list of the registered subjects.
+func (r *Registry) Subjects() ([]string, error) {
+	return r.SubjectsContext(context.Background())
+}
+
+// SubjectsContext same as `Subjects` but it accepts a context.
+func (r *Registry) SubjectsContext(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "Subjects"); err != nil {
+		return nil, err
+	}
+	return r.subjectNames(false), nil
+}
+
+// SubjectsIncludingDeleted returns a sorted list of the registered subjects, including the soft-deleted ones.
+func (r *Registry) SubjectsIncludingDeleted() ([]string, error) {
+	return r.SubjectsIncludingDeletedContext(context.Background())
+}
+
+// SubjectsIncludingDeletedContext same as `SubjectsIncludingDeleted` but it accepts a context.
+func (r *Registry) SubjectsIncludingDeletedContext(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "SubjectsIncludingDeleted"); err != nil {
+		return nil, err
+	}
+	return r.subjectNames(true), nil
+}
+
+func (r *Registry) subjectNames(deleted bool) []string {
+	subjects := make([]string, 0, len(r.subjects))
+	for subject := range r.subjects {
+		if len(r.live(subject, deleted)) > 0 {
+			subjects = append(subjects, subject)
+		}
+	}
+	sort.Strings(subjects)
+	return subjects
+}
+
+// Versions returns all schema version numbers registered for this subject.
+func (r *Registry) Versions(subject string) ([]int, error) {
+	return r.VersionsContext(context.Background(), subject)
+}
+
+// VersionsContext same as `Versions` but it accepts a context.
+func (r *Registry) VersionsContext(ctx context.Context, subject string) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "Versions", subject); err != nil {
+		return nil, err
+	}
+	return r.versions(subject, false)
+}
+
+// VersionsIncludingDeleted returns all schema version numbers registered for this subject, including the soft-deleted ones.
+func (r *Registry) VersionsIncludingDeleted(subject string) ([]int, error) {
+	return r.VersionsIncludingDeletedContext(context.Background(), subject)
+}
+
+// VersionsIncludingDeletedContext same as `VersionsIncludingDeleted` but it accepts a context.
+func (r *Registry) VersionsIncludingDeletedContext(ctx context.Context, subject string) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "VersionsIncludingDeleted", subject); err != nil {
+		return nil, err
+	}
+	return r.versions(subject, true)
+}
+
+func (r *Registry) versions(subject string, deleted bool) ([]int, error) {
+	schemas := r.live(subject, deleted)
+	if len(schemas) == 0 {
+		return nil, notFound(subjectNotFoundCode, "Subject '%s' not found.", subject)
+	}
+
+	versions := make([]int, 0, len(schemas))
+	for _, s := range schemas {
+		versions = append(versions, s.Version)
+	}
+	return versions, nil
+}
+
+// DeleteSubject soft-deletes the specified subject and deletes its compatibility level.
+func (r *Registry) DeleteSubject(subject string) ([]int, error) {
+	return r.DeleteSubjectContext(context.Background(), subject)
+}
+
+// DeleteSubjectContext same as `DeleteSubject` but it accepts a context.
+func (r *Registry) DeleteSubjectContext(ctx context.Context, subject string) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DeleteSubject", subject); err != nil {
+		return nil, err
+	}
+
+	if _, ok := r.subjects[subject]; !ok {
+		return nil, notFound(subjectNotFoundCode, "Subject '%s' not found.", subject)
+	}
+	versions, err := r.versions(subject, false)
+	if err != nil {
+		return nil, notFound(subjectSoftDeletedCode, "Subject '%s' was soft deleted.", subject)
+	}
+	for _, v := range versions {
+		r.deleted[subjectVersion{subject, v}] = true
+	}
+	delete(r.configs, subject)
+	return versions, nil
+}
+
+// PermanentlyDeleteSubject deletes the soft-deleted subject for good.
+func (r *Registry) PermanentlyDeleteSubject(subject string) ([]int, error) {
+	return r.PermanentlyDeleteSubjectContext(context.Background(), subject)
+}
+
+// PermanentlyDeleteSubjectContext same as `PermanentlyDeleteSubject` but it accepts a context.
+func (r *Registry) PermanentlyDeleteSubjectContext(ctx context.Context, subject string) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "PermanentlyDeleteSubject", subject); err != nil {
+		return nil, err
+	}
+
+	versions, err := r.versions(subject, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.live(subject, false)) > 0 {
+		return nil, notFound(subjectNotSoftDeletedCode, "Subject '%s' was not deleted first before being permanently deleted", subject)
+	}
+	for _, v := range versions {
+		r.removeVersion(subject, v)
+	}
+	return versions, nil
+}
+
+// DeleteSchemaVersion soft-deletes a version of the subject.
+func (r *Registry) DeleteSchemaVersion(subject string, versionID int) (int, error) {
+	return r.DeleteSchemaVersionContext(context.Background(), subject, versionID)
+}
+
+// DeleteSchemaVersionContext same as `DeleteSchemaVersion` but it accepts a context.
+func (r *Registry) DeleteSchemaVersionContext(ctx context.Context, subject string, versionID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DeleteSchemaVersion", subject, versionID); err != nil {
+		return 0, err
+	}
+
+	key := subjectVersion{subject, versionID}
+	if _, err := r.versionAt(subject, versionID); err != nil {
+		return 0, err
+	}
+	if r.deleted[key] {
+		return 0, notFound(versionSoftDeletedCode, "Subject '%s' Version %d was soft deleted.", subject, versionID)
+	}
+	r.deleted[key] = true
+	return versionID, nil
+}
+
+// PermanentlyDeleteSchemaVersion deletes the soft-deleted version of the subject for good.
+func (r *Registry) PermanentlyDeleteSchemaVersion(subject string, versionID int) (int, error) {
+	return r.PermanentlyDeleteSchemaVersionContext(context.Background(), subject, versionID)
+}
+
+// PermanentlyDeleteSchemaVersionContext same as `PermanentlyDeleteSchemaVersion` but it accepts a context.
+func (r *Registry) PermanentlyDeleteSchemaVersionContext(ctx context.Context, subject string, versionID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "PermanentlyDeleteSchemaVersion", subject, versionID); err != nil {
+		return 0, err
+	}
+
+	if _, err := r.versionAt(subject, versionID); err != nil {
+		return 0, err
+	}
+	if !r.deleted[subjectVersion{subject, versionID}] {
+		return 0, notFound(versionNotSoftDeletedCode, "Subject '%s' Version %d was not deleted first before being permanently deleted", subject, versionID)
+	}
+	r.removeVersion(subject, versionID)
+	return versionID, nil
+}
+
+// versionAt returns the version of the subject, soft-deleted or not.
+func (r *Registry) versionAt(subject string, version int) (schemaregistry.Schema, error) {
+	versions, ok := r.subjects[subject]
+	if !ok {
+		return schemaregistry.Schema{}, notFound(subjectNotFoundCode, "Subject '%s' not found.", subject)
+	}
+	for _, s := range versions {
+		if s.Version == version {
+			return s, nil
+		}
+	}
+	return schemaregistry.Schema{}, notFound(versionNotFoundCode, "Version %d not found.", version)
+}
+
+// removeVersion drops the version of the subject, the subject goes away with its last version
+// and the schema goes away when no subject refers to it anymore.
+func (r *Registry) removeVersion(subject string, version int) {
+	delete(r.deleted, subjectVersion{subject, version})
+
+	var (
+		kept    []schemaregistry.Schema
+		removed schemaregistry.Schema
+	)
+	for _, s := range r.subjects[subject] {
+		if s.Version == version {
+			removed = s
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if len(kept) == 0 {
+		delete(r.subjects, subject)
+	} else {
+		r.subjects[subject] = kept
+	}
+
+	for _, versions := range r.subjects {
+		for _, s := range versions {
+			if s.ID == removed.ID {
+				return
+			}
+		}
+	}
+	delete(r.ids, newSchemaKey(removed))
+	delete(r.schemas, removed.ID)
+}
+
+// GetConfig returns the compatibility level of the subject, or the global one when subject is empty.
+// A subject without its own level returns an empty `Config`, like the registry does.
+func (r *Registry) GetConfig(subject string) (schemaregistry.Config, error) {
+	return r.GetConfigContext(context.Background(), subject)
+}
+
+// GetConfigContext same as `GetConfig` but it accepts a context.
+func (r *Registry) GetConfigContext(ctx context.Context, subject string) (schemaregistry.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetConfig", subject); err != nil {
+		return schemaregistry.Config{}, err
+	}
+
+	cl, ok := r.configs[subject]
+	if !ok {
+		return schemaregistry.Config{}, nil
+	}
+	return schemaregistry.Config{CompatibilityLevel: cl.String()}, nil
+}
+
+// SetConfigLevel sets the compatibility level of the subject, or the global one when subject is empty.
+func (r *Registry) SetConfigLevel(cl schemaregistry.CompatibilityLevel, subject string) (schemaregistry.Config, error) {
+	return r.SetConfigLevelContext(context.Background(), cl, subject)
+}
+
+// SetConfigLevelContext same as `SetConfigLevel` but it accepts a context.
+func (r *Registry) SetConfigLevelContext(ctx context.Context, cl schemaregistry.CompatibilityLevel, subject string) (schemaregistry.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "SetConfigLevel", cl, subject); err != nil {
+		return schemaregistry.Config{}, err
+	}
+
+	r.configs[subject] = cl
+	return schemaregistry.Config{Compatibility: cl.String()}, nil
+}
+
+// SetConfigLevelFull sets the compatibility level of the subject to FULL.
+func (r *Registry) SetConfigLevelFull(subject string) (schemaregistry.Config, error) {
+	return r.SetConfigLevelContext(context.Background(), schemaregistry.Full, subject)
+}
+
+// SetConfigLevelFullContext same as `SetConfigLevelFull` but it accepts a context.
+func (r *Registry) SetConfigLevelFullContext(ctx context.Context, subject string) (schemaregistry.Config, error) {
+	return r.SetConfigLevelContext(ctx, schemaregistry.Full, subject)
+}
+
+// GetEffectiveConfig returns the compatibility level in effect for the subject, the global one
+// when the subject has no level of its own or when subject is empty.
+func (r *Registry) GetEffectiveConfig(subject string) (schemaregistry.Config, error) {
+	return r.GetEffectiveConfigContext(context.Background(), subject)
+}
+
+// GetEffectiveConfigContext same as `GetEffectiveConfig` but it accepts a context.
+func (r *Registry) GetEffectiveConfigContext(ctx context.Context, subject string) (schemaregistry.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetEffectiveConfig", subject); err != nil {
+		return schemaregistry.Config{}, err
+	}
+
+	cl, ok := r.configs[subject]
+	if !ok {
+		cl = r.configs[""]
+	}
+	return schemaregistry.Config{CompatibilityLevel: cl.String()}, nil
+}
+
+// DeleteConfig deletes the compatibility level of the subject, or resets the global one to BACKWARD
+// when subject is empty. It returns the deleted level.
+func (r *Registry) DeleteConfig(subject string) (schemaregistry.Config, error) {
+	return r.DeleteConfigContext(context.Background(), subject)
+}
+
+// DeleteConfigContext same as `DeleteConfig` but it accepts a context.
+func (r *Registry) DeleteConfigContext(ctx context.Context, subject string) (schemaregistry.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DeleteConfig", subject); err != nil {
+		return schemaregistry.Config{}, err
+	}
+
+	cl, ok := r.configs[subject]
+	if !ok {
+		return schemaregistry.Config{}, notFound(subjectCompatibilityNotFoundCode, "Subject '%s' does not have subject-level compatibility configured", subject)
+	}
+	if subject == "" {
+		r.configs[""] = schemaregistry.Backward
+	} else {
+		delete(r.configs, subject)
+	}
+	return schemaregistry.Config{CompatibilityLevel: cl.String()}, nil
+}
+
+// GetMode returns the mode of the subject, empty when the subject has no mode of its own,
+// or the global one when subject is empty.
+func (r *Registry) GetMode(subject string) (schemaregistry.Mode, error) {
+	return r.GetModeContext(context.Background(), subject)
+}
+
+// GetModeContext same as `GetMode` but it accepts a context.
+func (r *Registry) GetModeContext(ctx context.Context, subject string) (schemaregistry.Mode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetMode", subject); err != nil {
+		return "", err
+	}
+	return r.modes[subject], nil
+}
+
+// GetEffectiveMode returns the mode in effect for the subject, the global one
+// when the subject has no mode of its own or when subject is empty.
+func (r *Registry) GetEffectiveMode(subject string) (schemaregistry.Mode, error) {
+	return r.GetEffectiveModeContext(context.Background(), subject)
+}
+
+// GetEffectiveModeContext same as `GetEffectiveMode` but it accepts a context.
+func (r *Registry) GetEffectiveModeContext(ctx context.Context, subject string) (schemaregistry.Mode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetEffectiveMode", subject); err != nil {
+		return "", err
+	}
+	return r.mode(subject), nil
+}
+
+func (r *Registry) mode(subject string) schemaregistry.Mode {
+	if mode, ok := r.modes[subject]; ok {
+		return mode
+	}
+	return r.modes[""]
+}
+
+// SetMode sets the mode of the subject, or the global one when subject is empty.
+// It refuses to switch to IMPORT mode when there are schemas already, like the registry does.
+func (r *Registry) SetMode(mode schemaregistry.Mode, subject string) (schemaregistry.Mode, error) {
+	return r.SetModeContext(context.Background(), mode, subject)
+}
+
+// SetModeContext same as `SetMode` but it accepts a context.
+func (r *Registry) SetModeContext(ctx context.Context, mode schemaregistry.Mode, subject string) (schemaregistry.Mode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "SetMode", mode, subject); err != nil {
+		return "", err
+	}
+	if mode == schemaregistry.Import && r.hasSchemas(subject) {
+		return "", notPermitted("Cannot import since found existing subjects")
+	}
+	return r.setMode(mode, subject)
+}
+
+// SetModeForced same as `SetMode` but it switches to IMPORT mode even when there are schemas already.
+func (r *Registry) SetModeForced(mode schemaregistry.Mode, subject string) (schemaregistry.Mode, error) {
+	return r.SetModeForcedContext(context.Background(), mode, subject)
+}
+
+// SetModeForcedContext same as `SetModeForced` but it accepts a context.
+func (r *Registry) SetModeForcedContext(ctx context.Context, mode schemaregistry.Mode, subject string) (schemaregistry.Mode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "SetModeForced", mode, subject); err != nil {
+		return "", err
+	}
+	return r.setMode(mode, subject)
+}
+
+// hasSchemas reports whether the subject, or the whole registry when subject is empty, has any schema.
+func (r *Registry) hasSchemas(subject string) bool {
+	if subject == "" {
+		return len(r.subjectNames(false)) > 0
+	}
+	return len(r.live(subject, false)) > 0
+}
+
+func (r *Registry) setMode(mode schemaregistry.Mode, subject string) (schemaregistry.Mode, error) {
+	switch mode {
+	case schemaregistry.ReadWrite, schemaregistry.ReadOnly, schemaregistry.Import:
+	default:
+		return "", fmt.Errorf("mock: invalid mode %q", mode)
+	}
+	r.modes[subject] = mode
+	return mode, nil
+}
+
+// DeleteMode deletes the mode of the subject, it returns the deleted mode.
+func (r *Registry) DeleteMode(subject string) (schemaregistry.Mode, error) {
+	return r.DeleteModeContext(context.Background(), subject)
+}
+
+// DeleteModeContext same as `DeleteMode` but it accepts a context.
+func (r *Registry) DeleteModeContext(ctx context.Context, subject string) (schemaregistry.Mode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DeleteMode", subject); err != nil {
+		return "", err
+	}
+	if subject == "" {
+		return "", required("subject")
+	}
+
+	mode, ok := r.modes[subject]
+	if !ok {
+		return "", notFound(subjectModeNotFoundCode, "Subject '%s' does not have subject-level mode configured", subject)
+	}
+	delete(r.modes, subject)
+	return mode, nil
+}
@@ -0,0 +1,321 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_RegisterAndRead(t *testing.T) {
+	r := New()
+
+	id, err := r.RegisterNewSchema("subject", `"string"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	// same schema, same id and version.
+	id, err = r.RegisterNewSchema("subject", `"string"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	id, err = r.RegisterNewSchema("subject", `"int"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, id)
+
+	// same schema under another subject shares the id.
+	id, err = r.RegisterNewSchema("other", `"string"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	s, err := r.GetSchemaByID(2)
+	assert.NoError(t, err)
+	assert.Equal(t, `"int"`, s)
+
+	latest, err := r.GetLatestSchema("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Schema{Schema: `"int"`, Subject: "subject", Version: 2, ID: 2, SchemaType: schemaregistry.Avro}, latest)
+
+	versions, err := r.Versions("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+
+	subjects, err := r.Subjects()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other", "subject"}, subjects)
+
+	ok, found, err := r.IsRegistered("subject", `"string"`)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, found.Version)
+
+	deleted, err := r.DeleteSubject("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, deleted)
+}
+
+func TestRegistry_NotFound(t *testing.T) {
+	r := New()
+
+	_, err := r.GetSchemaByID(1)
+	assert.True(t, schemaregistry.IsSchemaNotFound(err))
+
+	_, err = r.GetLatestSchema("subject")
+	assert.True(t, schemaregistry.IsSubjectNotFound(err))
+
+	_, err = r.Versions("subject")
+	assert.True(t, schemaregistry.IsSubjectNotFound(err))
+}
+
+func TestRegistry_ProgrammableErrors(t *testing.T) {
+	r := New()
+	boom := errors.New("boom")
+
+	r.SetError("Subjects", boom)
+	_, err := r.Subjects()
+	assert.Equal(t, boom, err)
+	_, err = r.SubjectsContext(context.Background())
+	assert.Equal(t, boom, err)
+
+	r.SetError("Subjects", nil)
+	_, err = r.Subjects()
+	assert.NoError(t, err)
+}
+
+func TestRegistry_RecordsCalls(t *testing.T) {
+	r := New()
+	r.RegisterNewSchema("subject", `"string"`)
+	r.GetSchemaByIDContext(context.Background(), 1)
+	r.SetConfigLevel(schemaregistry.Full, "subject")
+
+	assert.Equal(t, []Call{
+		{Method: "RegisterNewSchema", Args: []interface{}{"subject", `"string"`}},
+		{Method: "GetSchemaByID", Args: []interface{}{1}},
+		{Method: "SetConfigLevel", Args: []interface{}{schemaregistry.Full, "subject"}},
+	}, r.Calls())
+	assert.Len(t, r.CallsOf("GetSchemaByID"), 1)
+
+	r.ResetCalls()
+	assert.Empty(t, r.Calls())
+}
+
+func TestRegistry_Compatibility(t *testing.T) {
+	r := New()
+	r.RegisterNewSchema("subject", `"string"`)
+
+	ok, err := r.IsLatestSchemaCompatible("subject", `"int"`)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	r.CompatibilityFunc = func(subject string, schema, registered schemaregistry.Schema) bool {
+		return schema.Schema == registered.Schema
+	}
+	ok, err = r.IsSchemaCompatible("subject", `"int"`, 1)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = r.IsSchemaCompatible("subject", `"int"`, 2)
+	assert.Error(t, err)
+}
+
+func TestRegistry_Config(t *testing.T) {
+	r := New()
+
+	cfg, err := r.GetConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, "BACKWARD", cfg.CompatibilityLevel)
+
+	cfg, err = r.GetConfig("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, "", cfg.CompatibilityLevel)
+
+	cfg, err = r.SetConfigLevelFull("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, "FULL", cfg.Compatibility)
+
+	cfg, err = r.GetConfig("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, "FULL", cfg.CompatibilityLevel)
+
+	cfg, err = r.DeleteConfig("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, "FULL", cfg.CompatibilityLevel)
+
+	cfg, err = r.GetEffectiveConfig("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, "BACKWARD", cfg.CompatibilityLevel)
+}
+
+func TestRegistry_WithCachedClient(t *testing.T) {
+	r := New()
+	cc := schemaregistry.NewCachedClient(r)
+
+	id, err := cc.RegisterNewSchema("subject", `"string"`)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = cc.GetSchemaByID(id)
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, r.CallsOf("GetSchemaByID"))
+}
+
+func TestRegistry_SchemaTypes(t *testing.T) {
+	r := New()
+
+	avroID, err := r.RegisterNewSchema("subject", `{"type":"string"}`)
+	assert.NoError(t, err)
+	jsonID, err := r.RegisterSchema("subject", schemaregistry.Schema{Schema: `{"type":"string"}`, SchemaType: schemaregistry.JSON})
+	assert.NoError(t, err)
+	assert.NotEqual(t, avroID, jsonID)
+
+	s, err := r.GetSchemaDetailsByID(jsonID)
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.JSON, s.SchemaType)
+
+	ok, found, err := r.LookupSchema("subject", schemaregistry.Schema{Schema: `{"type":"string"}`, SchemaType: schemaregistry.Avro})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, avroID, found.ID)
+}
+
+func TestRegistry_References(t *testing.T) {
+	r := New()
+	refs := []schemaregistry.SchemaReference{{Name: "Address", Subject: "address-value", Version: 1}}
+	user := schemaregistry.Schema{Schema: `{"type":"record","name":"User","fields":[{"name":"address","type":"Address"}]}`, References: refs}
+
+	_, err := r.RegisterSchema("user-value", user)
+	assert.Error(t, err)
+
+	addressID, err := r.RegisterNewSchema("address-value", `{"type":"record","name":"Address","fields":[]}`)
+	assert.NoError(t, err)
+	userID, err := r.RegisterSchema("user-value", user)
+	assert.NoError(t, err)
+	assert.NotEqual(t, addressID, userID)
+
+	s, err := r.GetSchemaDetailsByID(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, refs, s.References)
+
+	ids, err := r.ReferencedBy("address-value", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{userID}, ids)
+}
+
+func TestRegistry_Deletes(t *testing.T) {
+	r := New()
+	r.RegisterNewSchema("subject", `"string"`)
+	r.RegisterNewSchema("subject", `"int"`)
+
+	_, err := r.PermanentlyDeleteSchemaVersion("subject", 1)
+	assert.Equal(t, versionNotSoftDeletedCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	version, err := r.DeleteSchemaVersion("subject", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+	_, err = r.DeleteSchemaVersion("subject", 1)
+	assert.Equal(t, versionSoftDeletedCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	versions, _ := r.Versions("subject")
+	assert.Equal(t, []int{2}, versions)
+	versions, _ = r.VersionsIncludingDeleted("subject")
+	assert.Equal(t, []int{1, 2}, versions)
+
+	_, err = r.PermanentlyDeleteSchemaVersion("subject", 1)
+	assert.NoError(t, err)
+	_, err = r.GetSchemaByID(1)
+	assert.True(t, schemaregistry.IsSchemaNotFound(err))
+
+	versions, err = r.DeleteSubject("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, versions)
+
+	subjects, _ := r.Subjects()
+	assert.Empty(t, subjects)
+	subjects, _ = r.SubjectsIncludingDeleted()
+	assert.Equal(t, []string{"subject"}, subjects)
+
+	_, err = r.PermanentlyDeleteSubject("subject")
+	assert.NoError(t, err)
+	subjects, _ = r.SubjectsIncludingDeleted()
+	assert.Empty(t, subjects)
+}
+
+func TestRegistry_DeleteWithCachedClient(t *testing.T) {
+	r := New()
+	cc := schemaregistry.NewCachedClient(r)
+
+	_, err := cc.RegisterNewSchema("subject", `"string"`)
+	assert.NoError(t, err)
+	_, err = cc.GetSchemaBySubject("subject", 1)
+	assert.NoError(t, err)
+
+	_, err = cc.DeleteSubject("subject")
+	assert.NoError(t, err)
+
+	// the deleted version isn't served from the cache, the registration is sent again.
+	_, err = cc.GetSchemaBySubject("subject", 1)
+	assert.True(t, schemaregistry.IsSubjectNotFound(err))
+	_, err = cc.RegisterNewSchema("subject", `"string"`)
+	assert.NoError(t, err)
+	assert.Len(t, r.CallsOf("RegisterSchema"), 2)
+}
+
+func TestRegistry_Mode(t *testing.T) {
+	r := New()
+	r.RegisterNewSchema("subject", `"string"`)
+
+	_, err := r.ImportSchema("other", schemaregistry.Schema{Schema: `"int"`, ID: 10, Version: 5})
+	assert.Equal(t, operationNotPermittedCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	_, err = r.SetMode(schemaregistry.Import, "")
+	assert.Error(t, err)
+	mode, err := r.SetModeForced(schemaregistry.Import, "")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Import, mode)
+
+	id, err := r.ImportSchema("other", schemaregistry.Schema{Schema: `"int"`, ID: 10, Version: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, id)
+	s, err := r.GetLatestSchema("other")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, s.Version)
+
+	r.SetMode(schemaregistry.ReadOnly, "subject")
+	mode, _ = r.GetMode("subject")
+	assert.Equal(t, schemaregistry.ReadOnly, mode)
+	_, err = r.RegisterNewSchema("subject", `"long"`)
+	assert.Equal(t, operationNotPermittedCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	mode, err = r.DeleteMode("subject")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.ReadOnly, mode)
+	mode, _ = r.GetMode("subject")
+	assert.Equal(t, schemaregistry.Mode(""), mode)
+	mode, _ = r.GetEffectiveMode("subject")
+	assert.Equal(t, schemaregistry.Import, mode)
+}
+
+func TestRegistry_CompatibilityVerbose(t *testing.T) {
+	r := New()
+	r.RegisterNewSchema("subject", `"string"`)
+	r.RegisterNewSchema("subject", `"int"`)
+	r.CompatibilityFunc = func(subject string, schema, registered schemaregistry.Schema) bool {
+		return schema.Schema == registered.Schema
+	}
+
+	res, err := r.CheckLatestCompatibilityVerbose("subject", schemaregistry.Schema{Schema: `"int"`})
+	assert.NoError(t, err)
+	assert.True(t, res.IsCompatible)
+
+	res, err = r.CheckAllVersionsCompatibility("subject", schemaregistry.Schema{Schema: `"int"`})
+	assert.NoError(t, err)
+	assert.True(t, res.IsCompatible)
+
+	r.SetConfigLevel(schemaregistry.FullTransitive, "subject")
+	res, err = r.CheckAllVersionsCompatibility("subject", schemaregistry.Schema{Schema: `"int"`})
+	assert.NoError(t, err)
+	assert.False(t, res.IsCompatible)
+	assert.Len(t, res.Messages, 1)
+}
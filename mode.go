@@ -0,0 +1,144 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Mode is the mode of the registry or of a subject, it tells which writes are allowed.
+type Mode string
+
+// The modes supported by the registry.
+const (
+	// ReadWrite allows registrations and deletes, it's the default mode.
+	ReadWrite Mode = "READWRITE"
+	// ReadOnly rejects registrations and deletes.
+	ReadOnly Mode = "READONLY"
+	// Import allows registrations with an explicit ID and version only, look `ImportSchema`.
+	Import Mode = "IMPORT"
+)
+
+const modePath = "mode"
+
+type modeJSON struct {
+	Mode Mode `json:"mode"`
+}
+
+// GetMode returns the mode of the subject, empty when the subject has no mode of its own,
+// or the global mode when subject is empty.
+func (c *Client) GetMode(subject string) (Mode, error) {
+	return c.GetModeContext(context.Background(), subject)
+}
+
+// GetModeContext same as `GetMode` but it accepts a context to control the request's lifetime.
+func (c *Client) GetModeContext(ctx context.Context, subject string) (Mode, error) {
+	return c.getMode(ctx, subject, false)
+}
+
+// GetEffectiveMode returns the mode in effect for a subject, which is the global one
+// when the subject has no mode of its own ("defaultToGlobal").
+func (c *Client) GetEffectiveMode(subject string) (Mode, error) {
+	return c.GetEffectiveModeContext(context.Background(), subject)
+}
+
+// GetEffectiveModeContext same as `GetEffectiveMode` but it accepts a context to control the request's lifetime.
+func (c *Client) GetEffectiveModeContext(ctx context.Context, subject string) (Mode, error) {
+	return c.getMode(ctx, subject, true)
+}
+
+func (c *Client) getMode(ctx context.Context, subject string, defaultToGlobal bool) (Mode, error) {
+	// GET /mode[/(string: subject)]?defaultToGlobal=(boolean)
+	path := modePath
+	if subject != "" {
+		path += "/" + subject
+		if defaultToGlobal {
+			path += "?defaultToGlobal=true"
+		}
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, path, "", nil)
+	if subject != "" && checkNotFound(err, subjectModeNotFoundCode) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var res modeJSON
+	err = c.readJSON(resp, &res)
+	return res.Mode, err
+}
+
+// SetMode sets the mode of the subject, or the global one when subject is empty.
+// The registry refuses to switch to `Import` mode when there are schemas already, look `SetModeForced`.
+func (c *Client) SetMode(mode Mode, subject string) (Mode, error) {
+	return c.SetModeContext(context.Background(), mode, subject)
+}
+
+// SetModeContext same as `SetMode` but it accepts a context to control the request's lifetime.
+func (c *Client) SetModeContext(ctx context.Context, mode Mode, subject string) (Mode, error) {
+	return c.setMode(ctx, mode, subject, false)
+}
+
+// SetModeForced same as `SetMode` but it switches to `Import` mode even when there are schemas already.
+func (c *Client) SetModeForced(mode Mode, subject string) (Mode, error) {
+	return c.SetModeForcedContext(context.Background(), mode, subject)
+}
+
+// SetModeForcedContext same as `SetModeForced` but it accepts a context to control the request's lifetime.
+func (c *Client) SetModeForcedContext(ctx context.Context, mode Mode, subject string) (Mode, error) {
+	return c.setMode(ctx, mode, subject, true)
+}
+
+func (c *Client) setMode(ctx context.Context, mode Mode, subject string, force bool) (Mode, error) {
+	if mode == "" {
+		return "", errRequired("mode")
+	}
+
+	send, err := json.Marshal(modeJSON{Mode: mode})
+	if err != nil {
+		return "", err
+	}
+
+	// PUT /mode[/(string: subject)]?force=(boolean)
+	path := modePath
+	if subject != "" {
+		path += "/" + subject
+	}
+	if force {
+		path += "?force=true"
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, path, contentTypeSchemaJSON, send)
+	if err != nil {
+		return "", err
+	}
+
+	var res modeJSON
+	err = c.readJSON(resp, &res)
+	return res.Mode, err
+}
+
+// DeleteMode deletes the mode of the subject, which falls back to the global mode.
+// Returns the deleted mode.
+func (c *Client) DeleteMode(subject string) (Mode, error) {
+	return c.DeleteModeContext(context.Background(), subject)
+}
+
+// DeleteModeContext same as `DeleteMode` but it accepts a context to control the request's lifetime.
+func (c *Client) DeleteModeContext(ctx context.Context, subject string) (Mode, error) {
+	if subject == "" {
+		return "", errRequired("subject")
+	}
+
+	// DELETE /mode/(string: subject)
+	resp, err := c.do(ctx, http.MethodDelete, modePath+"/"+subject, "", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var res modeJSON
+	err = c.readJSON(resp, &res)
+	return res.Mode, err
+}
@@ -0,0 +1,90 @@
+package schemaregistry
+
+import "context"
+
+// Registry describes all the operations of the schema registry REST API.
+// `Client` and `CachedClient` implement it, code which depends on `Registry` instead of a `*Client`
+// can be tested against a fake implementation, look the "mock" package.
+type Registry interface {
+	RegisterNewSchema(subject string, avroSchema string) (int, error)
+	RegisterNewSchemaContext(ctx context.Context, subject string, avroSchema string) (int, error)
+	GetSchemaByID(subjectID int) (string, error)
+	GetSchemaByIDContext(ctx context.Context, subjectID int) (string, error)
+	GetSchemaBySubject(subject string, versionID int) (Schema, error)
+	GetSchemaBySubjectContext(ctx context.Context, subject string, versionID int) (Schema, error)
+	GetLatestSchema(subject string) (Schema, error)
+	GetLatestSchemaContext(ctx context.Context, subject string) (Schema, error)
+	IsRegistered(subject, schema string) (bool, Schema, error)
+	IsRegisteredContext(ctx context.Context, subject, schema string) (bool, Schema, error)
+	IsSchemaCompatible(subject string, avroSchema string, versionID int) (bool, error)
+	IsSchemaCompatibleContext(ctx context.Context, subject string, avroSchema string, versionID int) (bool, error)
+	IsLatestSchemaCompatible(subject string, avroSchema string) (bool, error)
+	IsLatestSchemaCompatibleContext(ctx context.Context, subject string, avroSchema string) (bool, error)
+
+	RegisterSchema(subject string, schema Schema) (int, error)
+	RegisterSchemaContext(ctx context.Context, subject string, schema Schema) (int, error)
+	GetSchemaDetailsByID(subjectID int) (Schema, error)
+	GetSchemaDetailsByIDContext(ctx context.Context, subjectID int) (Schema, error)
+	LookupSchema(subject string, schema Schema) (bool, Schema, error)
+	LookupSchemaContext(ctx context.Context, subject string, schema Schema) (bool, Schema, error)
+	CheckCompatibility(subject string, schema Schema, versionID int) (bool, error)
+	CheckCompatibilityContext(ctx context.Context, subject string, schema Schema, versionID int) (bool, error)
+	CheckLatestCompatibility(subject string, schema Schema) (bool, error)
+	CheckLatestCompatibilityContext(ctx context.Context, subject string, schema Schema) (bool, error)
+	CheckCompatibilityVerbose(subject string, schema Schema, versionID int) (CompatibilityResult, error)
+	CheckCompatibilityVerboseContext(ctx context.Context, subject string, schema Schema, versionID int) (CompatibilityResult, error)
+	CheckLatestCompatibilityVerbose(subject string, schema Schema) (CompatibilityResult, error)
+	CheckLatestCompatibilityVerboseContext(ctx context.Context, subject string, schema Schema) (CompatibilityResult, error)
+	CheckAllVersionsCompatibility(subject string, schema Schema) (CompatibilityResult, error)
+	CheckAllVersionsCompatibilityContext(ctx context.Context, subject string, schema Schema) (CompatibilityResult, error)
+	SchemaTypes() ([]SchemaType, error)
+	SchemaTypesContext(ctx context.Context) ([]SchemaType, error)
+	ReferencedBy(subject string, versionID int) ([]int, error)
+	ReferencedByContext(ctx context.Context, subject string, versionID int) ([]int, error)
+	ImportSchema(subject string, schema Schema) (int, error)
+	ImportSchemaContext(ctx context.Context, subject string, schema Schema) (int, error)
+
+	Subjects() ([]string, error)
+	SubjectsContext(ctx context.Context) ([]string, error)
+	Versions(subject string) ([]int, error)
+	VersionsContext(ctx context.Context, subject string) ([]int, error)
+	DeleteSubject(subject string) ([]int, error)
+	DeleteSubjectContext(ctx context.Context, subject string) ([]int, error)
+	SubjectsIncludingDeleted() ([]string, error)
+	SubjectsIncludingDeletedContext(ctx context.Context) ([]string, error)
+	VersionsIncludingDeleted(subject string) ([]int, error)
+	VersionsIncludingDeletedContext(ctx context.Context, subject string) ([]int, error)
+	PermanentlyDeleteSubject(subject string) ([]int, error)
+	PermanentlyDeleteSubjectContext(ctx context.Context, subject string) ([]int, error)
+	DeleteSchemaVersion(subject string, versionID int) (int, error)
+	DeleteSchemaVersionContext(ctx context.Context, subject string, versionID int) (int, error)
+	PermanentlyDeleteSchemaVersion(subject string, versionID int) (int, error)
+	PermanentlyDeleteSchemaVersionContext(ctx context.Context, subject string, versionID int) (int, error)
+
+	GetConfig(subject string) (Config, error)
+	GetConfigContext(ctx context.Context, subject string) (Config, error)
+	SetConfigLevel(cl CompatibilityLevel, subject string) (Config, error)
+	SetConfigLevelContext(ctx context.Context, cl CompatibilityLevel, subject string) (Config, error)
+	SetConfigLevelFull(subject string) (Config, error)
+	SetConfigLevelFullContext(ctx context.Context, subject string) (Config, error)
+	GetEffectiveConfig(subject string) (Config, error)
+	GetEffectiveConfigContext(ctx context.Context, subject string) (Config, error)
+	DeleteConfig(subject string) (Config, error)
+	DeleteConfigContext(ctx context.Context, subject string) (Config, error)
+
+	GetMode(subject string) (Mode, error)
+	GetModeContext(ctx context.Context, subject string) (Mode, error)
+	GetEffectiveMode(subject string) (Mode, error)
+	GetEffectiveModeContext(ctx context.Context, subject string) (Mode, error)
+	SetMode(mode Mode, subject string) (Mode, error)
+	SetModeContext(ctx context.Context, mode Mode, subject string) (Mode, error)
+	SetModeForced(mode Mode, subject string) (Mode, error)
+	SetModeForcedContext(ctx context.Context, mode Mode, subject string) (Mode, error)
+	DeleteMode(subject string) (Mode, error)
+	DeleteModeContext(ctx context.Context, subject string) (Mode, error)
+}
+
+var (
+	_ Registry = (*Client)(nil)
+	_ Registry = (*CachedClient)(nil)
+)
@@ -0,0 +1,829 @@
+// Package registrytest provides an in-process fake of the Confluent Schema Registry REST API,
+// backed by an in-memory storage, so the schemaregistry.Client and the applications
+// that use it can be integration tested without a running registry.
+//
+//	srv := registrytest.NewServer()
+//	defer srv.Close()
+//
+//	client, err := srv.Client()
+package registrytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+)
+
+// These numbers are used by the schema registry to communicate errors.
+const (
+	subjectNotFoundCode              = 40401
+	versionNotFoundCode              = 40402
+	schemaNotFoundCode               = 40403
+	subjectSoftDeletedCode           = 40404
+	subjectNotSoftDeletedCode        = 40405
+	versionSoftDeletedCode           = 40406
+	versionNotSoftDeletedCode        = 40407
+	subjectCompatibilityNotFoundCode = 40408
+	subjectModeNotFoundCode          = 40409
+	incompatibleSchemaCode           = 409
+	invalidSchemaCode                = 42201
+	invalidVersionCode               = 42202
+	invalidCompatibilityLevelCode    = 42203
+	invalidModeCode                  = 42204
+	operationNotPermittedCode        = 42205
+	referenceExistsCode              = 42206
+)
+
+const (
+	contentTypeHeaderKey  = "Content-Type"
+	contentTypeSchemaJSON = "application/vnd.schemaregistry.v1+json"
+
+	defaultCompatibilityLevel = "BACKWARD"
+	defaultMode               = "READWRITE"
+	latestVersion             = "latest"
+
+	avroType     = "AVRO"
+	jsonType     = "JSON"
+	protobufType = "PROTOBUF"
+)
+
+var (
+	compatibilityLevels = map[string]bool{
+		"NONE": true, "BACKWARD": true, "BACKWARD_TRANSITIVE": true, "FORWARD": true,
+		"FORWARD_TRANSITIVE": true, "FULL": true, "FULL_TRANSITIVE": true,
+	}
+	modes = map[string]bool{"READWRITE": true, "READONLY": true, "IMPORT": true}
+)
+
+// CompatibilityFunc decides whether "schema" is compatible with the "registered" schemas
+// under the given compatibility level, e.g. "BACKWARD". The registered schemas are the ones
+// the level requires to check against, oldest first. It returns the reasons of the incompatibility,
+// none means that the schema is compatible.
+type CompatibilityFunc func(level string, schema schemaregistry.Schema, registered []schemaregistry.Schema) []string
+
+type (
+	// Server is a fake schema registry, it's safe for concurrent use.
+	Server struct {
+		// URL is the base url of the server, e.g. "http://127.0.0.1:1234".
+		URL string
+
+		srv *httptest.Server
+
+		mu            sync.Mutex
+		compatibility CompatibilityFunc
+		subjects      map[string][]*version
+		schemas       map[int]storedSchema
+		ids           map[string]int // storedSchema.key() -> id
+		nextID        int
+		globalConfig  string
+		configs       map[string]string
+		globalMode    string
+		modes         map[string]string
+	}
+
+	version struct {
+		version int
+		id      int
+		deleted bool
+	}
+
+	storedSchema struct {
+		schema     string
+		schemaType string
+		references []schemaregistry.SchemaReference
+	}
+
+	apiError struct {
+		ErrorCode int    `json:"error_code"`
+		Message   string `json:"message"`
+	}
+
+	registerRequest struct {
+		Schema     string                           `json:"schema"`
+		SchemaType string                           `json:"schemaType,omitempty"`
+		References []schemaregistry.SchemaReference `json:"references,omitempty"`
+		ID         int                              `json:"id,omitempty"`
+		Version    int                              `json:"version,omitempty"`
+	}
+
+	schemaResponse struct {
+		Subject    string                           `json:"subject,omitempty"`
+		Version    int                              `json:"version,omitempty"`
+		ID         int                              `json:"id,omitempty"`
+		SchemaType string                           `json:"schemaType,omitempty"`
+		References []schemaregistry.SchemaReference `json:"references,omitempty"`
+		Schema     string                           `json:"schema"`
+	}
+
+	configJSON struct {
+		Compatibility      string `json:"compatibility,omitempty"`
+		CompatibilityLevel string `json:"compatibilityLevel,omitempty"`
+	}
+
+	modeJSON struct {
+		Mode string `json:"mode"`
+	}
+)
+
+// stored returns the schema of the request, a schema without a type is an Avro one.
+func (r registerRequest) stored() storedSchema {
+	st := storedSchema{schema: r.Schema, schemaType: r.SchemaType, references: r.References}
+	if st.schemaType == "" {
+		st.schemaType = avroType
+	}
+	return st
+}
+
+// key identifies the schema across subjects, the same schema always gets the same ID.
+func (st storedSchema) key() string {
+	var key strings.Builder
+	key.WriteString(st.schemaType)
+	for _, ref := range st.references {
+		fmt.Fprintf(&key, "\x00%s=%s:%d", ref.Name, ref.Subject, ref.Version)
+	}
+	key.WriteString("\x00" + st.schema)
+	return key.String()
+}
+
+// response returns the schema as it is returned by the registry, which omits the Avro type.
+func (st storedSchema) response() schemaResponse {
+	res := schemaResponse{Schema: st.schema, References: st.references}
+	if st.schemaType != avroType {
+		res.SchemaType = st.schemaType
+	}
+	return res
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d: %s", e.ErrorCode, e.Message)
+}
+
+func newError(code int, format string, args ...interface{}) *apiError {
+	return &apiError{ErrorCode: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewServer starts and returns a new, empty, fake schema registry.
+// The caller should call `Close` when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		subjects:     make(map[string][]*version),
+		schemas:      make(map[int]storedSchema),
+		ids:          make(map[string]int),
+		globalConfig: defaultCompatibilityLevel,
+		configs:      make(map[string]string),
+		globalMode:   defaultMode,
+		modes:        make(map[string]string),
+	}
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Client returns a new schemaregistry.Client connected to the server.
+func (s *Server) Client(options ...schemaregistry.Option) (*schemaregistry.Client, error) {
+	return schemaregistry.NewClientFromURL(s.URL, options...)
+}
+
+// SetCompatibilityFunc sets the function which checks the compatibility of new schemas,
+// by default every schema is compatible.
+func (s *Server) SetCompatibilityFunc(fn CompatibilityFunc) {
+	s.mu.Lock()
+	s.compatibility = fn
+	s.mu.Unlock()
+}
+
+// ServeHTTP implements the http.Handler, it serves the schema registry REST API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, err := s.route(r)
+	if err != nil {
+		// the registry's error codes start with the http status code, e.g. 40401 is a 404.
+		status := err.ErrorCode
+		for status >= 1000 {
+			status /= 10
+		}
+		writeJSON(w, status, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set(contentTypeHeaderKey, contentTypeSchemaJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) route(r *http.Request) (interface{}, *apiError) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+	deleted := query.Get("deleted") == "true"
+	permanent := query.Get("permanent") == "true"
+
+	switch {
+	// GET /subjects
+	case r.Method == http.MethodGet && len(parts) == 1 && parts[0] == "subjects":
+		return s.listSubjects(deleted), nil
+	// POST /subjects/(string: subject)
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[0] == "subjects":
+		var req registerRequest
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return s.lookup(parts[1], req, deleted)
+	// DELETE /subjects/(string: subject)
+	case r.Method == http.MethodDelete && len(parts) == 2 && parts[0] == "subjects":
+		return s.deleteSubject(parts[1], permanent)
+	// GET /subjects/(string: subject)/versions
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "subjects" && parts[2] == "versions":
+		return s.listVersions(parts[1], deleted)
+	// POST /subjects/(string: subject)/versions
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "subjects" && parts[2] == "versions":
+		var req registerRequest
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		return s.register(parts[1], req)
+	// GET /subjects/(string: subject)/versions/(versionId: version)
+	case r.Method == http.MethodGet && len(parts) == 4 && parts[0] == "subjects" && parts[2] == "versions":
+		v, err := s.version(parts[1], parts[3], deleted)
+		if err != nil {
+			return nil, err
+		}
+		return s.schemaResponse(parts[1], v), nil
+	// GET /subjects/(string: subject)/versions/(versionId: version)/schema
+	case r.Method == http.MethodGet && len(parts) == 5 && parts[0] == "subjects" && parts[2] == "versions" && parts[4] == "schema":
+		v, err := s.version(parts[1], parts[3], deleted)
+		if err != nil {
+			return nil, err
+		}
+		if st := s.schemas[v.id]; st.schemaType != protobufType {
+			return json.RawMessage(st.schema), nil
+		}
+		return s.schemas[v.id].schema, nil
+	// GET /subjects/(string: subject)/versions/(versionId: version)/referencedby
+	case r.Method == http.MethodGet && len(parts) == 5 && parts[0] == "subjects" && parts[2] == "versions" && parts[4] == "referencedby":
+		v, err := s.version(parts[1], parts[3], deleted)
+		if err != nil {
+			return nil, err
+		}
+		return s.referencedBy(parts[1], v), nil
+	// DELETE /subjects/(string: subject)/versions/(versionId: version)
+	case r.Method == http.MethodDelete && len(parts) == 4 && parts[0] == "subjects" && parts[2] == "versions":
+		return s.deleteVersion(parts[1], parts[3], permanent)
+	// GET /schemas/types
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "schemas" && parts[1] == "types":
+		return []string{jsonType, protobufType, avroType}, nil
+	// GET /schemas/ids/{int: id}
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "schemas" && parts[1] == "ids":
+		return s.schemaByID(parts[2])
+	// POST /compatibility/subjects/(string: subject)/versions[/(versionId: version)]
+	case r.Method == http.MethodPost && len(parts) >= 4 && len(parts) <= 5 && parts[0] == "compatibility" && parts[1] == "subjects" && parts[3] == "versions":
+		var req registerRequest
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		versionID := ""
+		if len(parts) == 5 {
+			versionID = parts[4]
+		}
+		return s.checkCompatibility(parts[2], versionID, req, query.Get("verbose") == "true")
+	// /config[/(string: subject)]
+	case (len(parts) == 1 || len(parts) == 2) && parts[0] == "config":
+		subject := ""
+		if len(parts) == 2 {
+			subject = parts[1]
+		}
+		return s.config(r, subject, query.Get("defaultToGlobal") == "true")
+	// /mode[/(string: subject)]
+	case (len(parts) == 1 || len(parts) == 2) && parts[0] == "mode":
+		subject := ""
+		if len(parts) == 2 {
+			subject = parts[1]
+		}
+		return s.mode(r, subject, query.Get("defaultToGlobal") == "true", query.Get("force") == "true")
+	}
+
+	return nil, &apiError{ErrorCode: http.StatusNotFound, Message: "HTTP 404 Not Found"}
+}
+
+func decode(r *http.Request, v interface{}) *apiError {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return &apiError{ErrorCode: http.StatusBadRequest, Message: err.Error()}
+	}
+	if err = json.Unmarshal(b, v); err != nil {
+		return &apiError{ErrorCode: http.StatusBadRequest, Message: "Unrecognized request body: " + err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) listSubjects(deleted bool) []string {
+	subjects := make([]string, 0, len(s.subjects))
+	for subject, versions := range s.subjects {
+		if len(liveVersions(versions, deleted)) > 0 {
+			subjects = append(subjects, subject)
+		}
+	}
+	sort.Strings(subjects)
+	return subjects
+}
+
+// liveVersions returns the versions which are not soft deleted, or all of them when "deleted" is true.
+func liveVersions(versions []*version, deleted bool) []*version {
+	var live []*version
+	for _, v := range versions {
+		if deleted || !v.deleted {
+			live = append(live, v)
+		}
+	}
+	return live
+}
+
+func (s *Server) subjectVersions(subject string, deleted bool) ([]*version, *apiError) {
+	versions := liveVersions(s.subjects[subject], deleted)
+	if len(versions) == 0 {
+		return nil, newError(subjectNotFoundCode, "Subject '%s' not found.", subject)
+	}
+	return versions, nil
+}
+
+func (s *Server) listVersions(subject string, deleted bool) ([]int, *apiError) {
+	versions, err := s.subjectVersions(subject, deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	nums := make([]int, 0, len(versions))
+	for _, v := range versions {
+		nums = append(nums, v.version)
+	}
+	return nums, nil
+}
+
+func (s *Server) version(subject, versionID string, deleted bool) (*version, *apiError) {
+	versions, err := s.subjectVersions(subject, deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	if versionID == latestVersion || versionID == "-1" {
+		return versions[len(versions)-1], nil
+	}
+
+	num, convErr := strconv.Atoi(versionID)
+	if convErr != nil || num <= 0 {
+		return nil, newError(invalidVersionCode, "The specified version '%s' is not a valid version id. Allowed values are between [1, 2^31-1] and the string \"latest\"", versionID)
+	}
+
+	for _, v := range versions {
+		if v.version == num {
+			return v, nil
+		}
+	}
+	return nil, newError(versionNotFoundCode, "Version %d not found.", num)
+}
+
+func (s *Server) schemaResponse(subject string, v *version) schemaResponse {
+	res := s.schemas[v.id].response()
+	res.Subject = subject
+	res.Version = v.version
+	res.ID = v.id
+	return res
+}
+
+func (s *Server) schemaByID(rawID string) (interface{}, *apiError) {
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		return nil, &apiError{ErrorCode: http.StatusNotFound, Message: "HTTP 404 Not Found"}
+	}
+
+	st, ok := s.schemas[id]
+	if !ok {
+		return nil, newError(schemaNotFoundCode, "Schema %d not found", id)
+	}
+	return st.response(), nil
+}
+
+func (s *Server) lookup(subject string, req registerRequest, deleted bool) (interface{}, *apiError) {
+	versions, err := s.subjectVersions(subject, deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	key := req.stored().key()
+	for _, v := range versions {
+		if s.schemas[v.id].key() == key {
+			return s.schemaResponse(subject, v), nil
+		}
+	}
+	return nil, newError(schemaNotFoundCode, "Schema not found")
+}
+
+func (s *Server) validateSchema(st storedSchema) *apiError {
+	for _, ref := range st.references {
+		if _, err := s.version(ref.Subject, strconv.Itoa(ref.Version), false); err != nil {
+			return newError(invalidSchemaCode, "Invalid schema, reference %s (subject %s, version %d) not found", ref.Name, ref.Subject, ref.Version)
+		}
+	}
+
+	switch st.schemaType {
+	case avroType, jsonType:
+		if !json.Valid([]byte(st.schema)) {
+			return newError(invalidSchemaCode, "Invalid schema %s", st.schema)
+		}
+	case protobufType:
+		if strings.TrimSpace(st.schema) == "" {
+			return newError(invalidSchemaCode, "Invalid schema %s", st.schema)
+		}
+	default:
+		return newError(invalidSchemaCode, "Invalid schema type %s", st.schemaType)
+	}
+	return nil
+}
+
+func (s *Server) register(subject string, req registerRequest) (interface{}, *apiError) {
+	mode := s.effectiveMode(subject)
+	if mode == "READONLY" {
+		return nil, newError(operationNotPermittedCode, "Subject %s is in read-only mode", subject)
+	}
+	if (req.ID > 0 || req.Version > 0) && mode != "IMPORT" {
+		return nil, newError(operationNotPermittedCode, "Subject %s is not in import mode", subject)
+	}
+	st := req.stored()
+	if err := s.validateSchema(st); err != nil {
+		return nil, err
+	}
+
+	all := s.subjects[subject]
+	for _, v := range liveVersions(all, false) {
+		if s.schemas[v.id].key() == st.key() && (req.ID == 0 || req.ID == v.id) {
+			return map[string]int{"id": v.id}, nil
+		}
+	}
+
+	if mode != "IMPORT" {
+		if messages := s.incompatibilities(subject, req, ""); len(messages) > 0 {
+			return nil, newError(incompatibleSchemaCode, "Schema being registered is incompatible with an earlier schema for subject \"%s\", details: %s", subject, strings.Join(messages, "; "))
+		}
+	}
+
+	id, ok := s.ids[st.key()]
+	switch {
+	case req.ID > 0:
+		if existing, taken := s.schemas[req.ID]; taken && existing.key() != st.key() {
+			return nil, newError(operationNotPermittedCode, "Overwrite new schema with id %d is not permitted.", req.ID)
+		}
+		id = req.ID
+	case !ok:
+		s.nextID++
+		for _, taken := s.schemas[s.nextID]; taken; _, taken = s.schemas[s.nextID] {
+			s.nextID++
+		}
+		id = s.nextID
+	}
+	s.schemas[id] = st
+	if _, ok := s.ids[st.key()]; !ok {
+		s.ids[st.key()] = id
+	}
+
+	num := req.Version
+	if num == 0 {
+		for _, v := range all {
+			if v.version > num {
+				num = v.version
+			}
+		}
+		num++
+	} else {
+		for _, v := range all {
+			if v.version == num {
+				return nil, newError(operationNotPermittedCode, "Version %d of subject %s already exists", num, subject)
+			}
+		}
+	}
+
+	all = append(all, &version{version: num, id: id})
+	sort.Slice(all, func(i, j int) bool { return all[i].version < all[j].version })
+	s.subjects[subject] = all
+	return map[string]int{"id": id}, nil
+}
+
+// incompatibilities checks the schema against the registered versions of the subject which the
+// compatibility level requires, or against a specific version when "versionID" is not empty.
+func (s *Server) incompatibilities(subject string, req registerRequest, versionID string) []string {
+	level := s.effectiveConfig(subject)
+	if s.compatibility == nil || level == "NONE" {
+		return nil
+	}
+
+	versions := liveVersions(s.subjects[subject], false)
+	if len(versions) == 0 {
+		return nil
+	}
+
+	var against []*version
+	switch {
+	case versionID != "":
+		v, err := s.version(subject, versionID, false)
+		if err != nil {
+			return nil
+		}
+		against = []*version{v}
+	case strings.HasSuffix(level, "_TRANSITIVE"):
+		against = versions
+	default:
+		against = versions[len(versions)-1:]
+	}
+
+	registered := make([]schemaregistry.Schema, 0, len(against))
+	for _, v := range against {
+		registered = append(registered, s.schema(subject, v))
+	}
+
+	st := req.stored()
+	return s.compatibility(level, schemaregistry.Schema{
+		Schema:     st.schema,
+		Subject:    subject,
+		SchemaType: schemaregistry.SchemaType(st.schemaType),
+		References: st.references,
+	}, registered)
+}
+
+func (s *Server) schema(subject string, v *version) schemaregistry.Schema {
+	st := s.schemas[v.id]
+	return schemaregistry.Schema{
+		Schema:     st.schema,
+		Subject:    subject,
+		Version:    v.version,
+		ID:         v.id,
+		SchemaType: schemaregistry.SchemaType(st.schemaType),
+		References: st.references,
+	}
+}
+
+func (s *Server) checkCompatibility(subject, versionID string, req registerRequest, verbose bool) (interface{}, *apiError) {
+	if versionID != "" {
+		if _, err := s.version(subject, versionID, false); err != nil {
+			return nil, err
+		}
+	} else if _, err := s.subjectVersions(subject, false); err != nil {
+		return nil, err
+	}
+	if err := s.validateSchema(req.stored()); err != nil {
+		return nil, err
+	}
+
+	messages := s.incompatibilities(subject, req, versionID)
+	res := map[string]interface{}{"is_compatible": len(messages) == 0}
+	if verbose {
+		if messages == nil {
+			messages = []string{}
+		}
+		res["messages"] = messages
+	}
+	return res, nil
+}
+
+func (s *Server) deleteSubject(subject string, permanent bool) (interface{}, *apiError) {
+	all, ok := s.subjects[subject]
+	if !ok {
+		return nil, newError(subjectNotFoundCode, "Subject '%s' not found.", subject)
+	}
+
+	live := liveVersions(all, false)
+	nums := make([]int, 0, len(all))
+
+	if permanent {
+		if len(live) > 0 {
+			return nil, newError(subjectNotSoftDeletedCode, "Subject '%s' was not deleted first before being permanently deleted", subject)
+		}
+		delete(s.subjects, subject)
+		for _, v := range all {
+			nums = append(nums, v.version)
+			s.removeIfUnused(v.id)
+		}
+		delete(s.configs, subject)
+		delete(s.modes, subject)
+		return nums, nil
+	}
+
+	if len(live) == 0 {
+		return nil, newError(subjectSoftDeletedCode, "Subject '%s' was soft deleted.Set permanent=true to delete permanently", subject)
+	}
+	for _, v := range live {
+		if err := s.checkNotReferenced(subject, v); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range live {
+		v.deleted = true
+		nums = append(nums, v.version)
+	}
+	delete(s.configs, subject)
+	return nums, nil
+}
+
+func (s *Server) deleteVersion(subject, versionID string, permanent bool) (interface{}, *apiError) {
+	v, err := s.version(subject, versionID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if !permanent {
+		if v.deleted {
+			return nil, newError(versionSoftDeletedCode, "Subject '%s' Version %d was soft deleted.Set permanent=true to delete permanently", subject, v.version)
+		}
+		if err := s.checkNotReferenced(subject, v); err != nil {
+			return nil, err
+		}
+		v.deleted = true
+		return v.version, nil
+	}
+
+	if !v.deleted {
+		return nil, newError(versionNotSoftDeletedCode, "Subject '%s' Version %d was not deleted first before being permanently deleted", subject, v.version)
+	}
+
+	all := s.subjects[subject]
+	for i, candidate := range all {
+		if candidate == v {
+			s.subjects[subject] = append(all[:i:i], all[i+1:]...)
+			break
+		}
+	}
+	if len(s.subjects[subject]) == 0 {
+		delete(s.subjects, subject)
+	}
+	s.removeIfUnused(v.id)
+	return v.version, nil
+}
+
+// referencedBy returns the IDs of the schemas which refer to the subject's version.
+func (s *Server) referencedBy(subject string, v *version) []int {
+	ids := []int{}
+	for _, versions := range s.subjects {
+		for _, candidate := range liveVersions(versions, false) {
+			for _, ref := range s.schemas[candidate.id].references {
+				if ref.Subject == subject && ref.Version == v.version {
+					ids = append(ids, candidate.id)
+					break
+				}
+			}
+		}
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *Server) checkNotReferenced(subject string, v *version) *apiError {
+	if len(s.referencedBy(subject, v)) > 0 {
+		return newError(referenceExistsCode, "One or more references exist to the schema {subject=%s,version=%d}.", subject, v.version)
+	}
+	return nil
+}
+
+// removeIfUnused drops the schema of a permanently deleted version when no other version refers to it.
+func (s *Server) removeIfUnused(id int) {
+	for _, versions := range s.subjects {
+		for _, v := range versions {
+			if v.id == id {
+				return
+			}
+		}
+	}
+
+	delete(s.ids, s.schemas[id].key())
+	delete(s.schemas, id)
+}
+
+func (s *Server) effectiveConfig(subject string) string {
+	if level, ok := s.configs[subject]; ok {
+		return level
+	}
+	return s.globalConfig
+}
+
+func (s *Server) config(r *http.Request, subject string, defaultToGlobal bool) (interface{}, *apiError) {
+	switch r.Method {
+	case http.MethodGet:
+		if subject == "" {
+			return configJSON{CompatibilityLevel: s.globalConfig}, nil
+		}
+		level, ok := s.configs[subject]
+		if !ok && !defaultToGlobal {
+			return nil, newError(subjectCompatibilityNotFoundCode, "Subject '%s' does not have subject-level compatibility configured", subject)
+		}
+		if !ok {
+			level = s.globalConfig
+		}
+		return configJSON{CompatibilityLevel: level}, nil
+	case http.MethodPut:
+		var req configJSON
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		if !compatibilityLevels[req.Compatibility] {
+			return nil, newError(invalidCompatibilityLevelCode, "Invalid compatibility level. Valid values are none, backward, forward, full, backward_transitive, forward_transitive, and full_transitive")
+		}
+		if subject == "" {
+			s.globalConfig = req.Compatibility
+		} else {
+			s.configs[subject] = req.Compatibility
+		}
+		return configJSON{Compatibility: req.Compatibility}, nil
+	case http.MethodDelete:
+		if subject == "" {
+			previous := s.globalConfig
+			s.globalConfig = defaultCompatibilityLevel
+			return configJSON{CompatibilityLevel: previous}, nil
+		}
+		level, ok := s.configs[subject]
+		if !ok {
+			return nil, newError(subjectCompatibilityNotFoundCode, "Subject '%s' does not have subject-level compatibility configured", subject)
+		}
+		delete(s.configs, subject)
+		return configJSON{CompatibilityLevel: level}, nil
+	}
+
+	return nil, &apiError{ErrorCode: http.StatusMethodNotAllowed, Message: "HTTP 405 Method Not Allowed"}
+}
+
+func (s *Server) effectiveMode(subject string) string {
+	if mode, ok := s.modes[subject]; ok {
+		return mode
+	}
+	return s.globalMode
+}
+
+func (s *Server) mode(r *http.Request, subject string, defaultToGlobal, force bool) (interface{}, *apiError) {
+	switch r.Method {
+	case http.MethodGet:
+		if subject == "" {
+			return modeJSON{Mode: s.globalMode}, nil
+		}
+		mode, ok := s.modes[subject]
+		if !ok && !defaultToGlobal {
+			return nil, newError(subjectModeNotFoundCode, "Subject '%s' does not have subject-level mode configured", subject)
+		}
+		if !ok {
+			mode = s.globalMode
+		}
+		return modeJSON{Mode: mode}, nil
+	case http.MethodPut:
+		var req modeJSON
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+		if !modes[req.Mode] {
+			return nil, newError(invalidModeCode, "Invalid mode. Valid values are READWRITE, READONLY and IMPORT.")
+		}
+		if req.Mode == "IMPORT" && !force && s.hasSchemas(subject) {
+			return nil, newError(operationNotPermittedCode, "Cannot import since found existing subjects")
+		}
+		if subject == "" {
+			s.globalMode = req.Mode
+		} else {
+			s.modes[subject] = req.Mode
+		}
+		return req, nil
+	case http.MethodDelete:
+		if subject == "" {
+			previous := s.globalMode
+			s.globalMode = defaultMode
+			return modeJSON{Mode: previous}, nil
+		}
+		mode, ok := s.modes[subject]
+		if !ok {
+			return nil, newError(subjectModeNotFoundCode, "Subject '%s' does not have subject-level mode configured", subject)
+		}
+		delete(s.modes, subject)
+		return modeJSON{Mode: mode}, nil
+	}
+
+	return nil, &apiError{ErrorCode: http.StatusMethodNotAllowed, Message: "HTTP 405 Method Not Allowed"}
+}
+
+// hasSchemas reports whether the subject, or the whole registry when subject is empty, has any schema.
+func (s *Server) hasSchemas(subject string) bool {
+	if subject == "" {
+		return len(s.listSubjects(false)) > 0
+	}
+	return len(liveVersions(s.subjects[subject], false)) > 0
+}
@@ -0,0 +1,426 @@
+package registrytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	schemaV1 = `{"type":"record","name":"User","fields":[{"name":"name","type":"string"}]}`
+	schemaV2 = `{"type":"record","name":"User","fields":[{"name":"name","type":"string"},{"name":"age","type":"int","default":0}]}`
+)
+
+func newTestClient(t *testing.T) (*Server, *schemaregistry.Client) {
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+
+	c, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, c
+}
+
+// send makes a raw request to the server and decodes the response into "v".
+func send(t *testing.T, srv *Server, method, path string, body, v interface{}) int {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req, err := http.NewRequest(method, srv.URL+path, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		json.NewDecoder(resp.Body).Decode(v)
+	}
+	return resp.StatusCode
+}
+
+func TestServer_RegisterAndRead(t *testing.T) {
+	_, c := newTestClient(t)
+
+	id1, err := c.RegisterNewSchema("users-value", schemaV1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id1)
+
+	id2, err := c.RegisterNewSchema("users-value", schemaV2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, id2)
+
+	// registering again returns the existing id.
+	id, err := c.RegisterNewSchema("users-value", schemaV1)
+	assert.NoError(t, err)
+	assert.Equal(t, id1, id)
+
+	// the same schema under another subject shares the id.
+	id, err = c.RegisterNewSchema("users-key", schemaV1)
+	assert.NoError(t, err)
+	assert.Equal(t, id1, id)
+
+	subjects, err := c.Subjects()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"users-key", "users-value"}, subjects)
+
+	versions, err := c.Versions("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+
+	s, err := c.GetSchemaByID(id2)
+	assert.NoError(t, err)
+	assert.Equal(t, schemaV2, s)
+
+	latest, err := c.GetLatestSchema("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Schema{Schema: schemaV2, Subject: "users-value", Version: 2, ID: id2, SchemaType: schemaregistry.Avro}, latest)
+
+	first, err := c.GetSchemaBySubject("users-value", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, id1, first.ID)
+
+	ok, found, err := c.IsRegistered("users-value", schemaV2)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, found.Version)
+
+	ok, _, err = c.IsRegistered("users-value", `"string"`)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestServer_Errors(t *testing.T) {
+	srv, c := newTestClient(t)
+
+	_, err := c.GetSchemaByID(42)
+	assert.True(t, schemaregistry.IsSchemaNotFound(err))
+
+	_, err = c.Versions("missing")
+	assert.True(t, schemaregistry.IsSubjectNotFound(err))
+
+	_, err = c.RegisterNewSchema("users-value", "{not json")
+	assert.Equal(t, invalidSchemaCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	c.RegisterNewSchema("users-value", schemaV1)
+	_, err = c.GetSchemaBySubject("users-value", 5)
+	assert.Equal(t, versionNotFoundCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	var apiErr apiError
+	status := send(t, srv, http.MethodGet, "/subjects/users-value/versions/abc", nil, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, invalidVersionCode, apiErr.ErrorCode)
+}
+
+func TestServer_Compatibility(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.SetCompatibilityFunc(func(level string, schema schemaregistry.Schema, registered []schemaregistry.Schema) []string {
+		assert.Equal(t, "BACKWARD", level)
+		if schema.Schema == `"string"` {
+			return []string{"type changed"}
+		}
+		return nil
+	})
+
+	_, err := c.RegisterNewSchema("users-value", schemaV1)
+	assert.NoError(t, err)
+
+	ok, err := c.IsLatestSchemaCompatible("users-value", schemaV2)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = c.IsSchemaCompatible("users-value", `"string"`, 1)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = c.RegisterNewSchema("users-value", `"string"`)
+	assert.Equal(t, incompatibleSchemaCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	res, err := c.CheckAllVersionsCompatibility("users-value", schemaregistry.Schema{Schema: `"string"`})
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.CompatibilityResult{IsCompatible: false, Messages: []string{"type changed"}}, res)
+
+	res, err = c.CheckCompatibilityVerbose("users-value", schemaregistry.Schema{Schema: schemaV2}, 1)
+	assert.NoError(t, err)
+	assert.True(t, res.IsCompatible)
+
+	_, err = c.SetConfigLevel(schemaregistry.None, "users-value")
+	assert.NoError(t, err)
+	_, err = c.RegisterNewSchema("users-value", `"string"`)
+	assert.NoError(t, err)
+}
+
+func TestServer_Config(t *testing.T) {
+	srv, c := newTestClient(t)
+
+	cfg, err := c.GetConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, "BACKWARD", cfg.CompatibilityLevel)
+
+	cfg, err = c.SetConfigLevelFull("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "FULL", cfg.Compatibility)
+
+	cfg, err = c.GetConfig("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "FULL", cfg.CompatibilityLevel)
+
+	var apiErr apiError
+	status := send(t, srv, http.MethodPut, "/config", map[string]string{"compatibility": "SOMETIMES"}, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, invalidCompatibilityLevelCode, apiErr.ErrorCode)
+
+	var got configJSON
+	send(t, srv, http.MethodGet, "/config/orders-value?defaultToGlobal=true", nil, &got)
+	assert.Equal(t, "BACKWARD", got.CompatibilityLevel)
+
+	status = send(t, srv, http.MethodDelete, "/config/users-value", nil, &got)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "FULL", got.CompatibilityLevel)
+}
+
+func TestServer_Mode(t *testing.T) {
+	srv, c := newTestClient(t)
+
+	var mode modeJSON
+	send(t, srv, http.MethodGet, "/mode", nil, &mode)
+	assert.Equal(t, "READWRITE", mode.Mode)
+
+	// registering with an explicit id requires IMPORT mode.
+	var apiErr apiError
+	status := send(t, srv, http.MethodPost, "/subjects/users-value/versions", registerRequest{Schema: schemaV1, ID: 100, Version: 3}, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, operationNotPermittedCode, apiErr.ErrorCode)
+
+	send(t, srv, http.MethodPut, "/mode", modeJSON{Mode: "IMPORT"}, &mode)
+	assert.Equal(t, "IMPORT", mode.Mode)
+
+	var id map[string]int
+	status = send(t, srv, http.MethodPost, "/subjects/users-value/versions", registerRequest{Schema: schemaV1, ID: 100, Version: 3}, &id)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 100, id["id"])
+
+	s, err := c.GetLatestSchema("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, s.Version)
+	assert.Equal(t, 100, s.ID)
+
+	// the registry is not empty anymore.
+	send(t, srv, http.MethodPut, "/mode", modeJSON{Mode: "READWRITE"}, nil)
+	status = send(t, srv, http.MethodPut, "/mode", modeJSON{Mode: "IMPORT"}, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	status = send(t, srv, http.MethodPut, "/mode?force=true", modeJSON{Mode: "IMPORT"}, nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	send(t, srv, http.MethodPut, "/mode/users-value", modeJSON{Mode: "READONLY"}, nil)
+	_, err = c.RegisterNewSchema("users-value", schemaV2)
+	assert.Equal(t, operationNotPermittedCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	status = send(t, srv, http.MethodGet, "/mode/orders-value", nil, &apiErr)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, subjectModeNotFoundCode, apiErr.ErrorCode)
+}
+
+func TestServer_Deletes(t *testing.T) {
+	srv, c := newTestClient(t)
+	c.RegisterNewSchema("users-value", schemaV1)
+	c.RegisterNewSchema("users-value", schemaV2)
+
+	var apiErr apiError
+	status := send(t, srv, http.MethodDelete, "/subjects/users-value/versions/1?permanent=true", nil, &apiErr)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, versionNotSoftDeletedCode, apiErr.ErrorCode)
+
+	deleted, err := c.DeleteSchemaVersion("users-value", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	versions, err := c.Versions("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, versions)
+
+	all, err := c.VersionsIncludingDeleted("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, all)
+
+	deleted, err = c.PermanentlyDeleteSchemaVersion("users-value", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	removed, err := c.DeleteSubject("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, removed)
+
+	_, err = c.DeleteSubject("users-value")
+	assert.Equal(t, subjectSoftDeletedCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	subjects, err := c.Subjects()
+	assert.NoError(t, err)
+	assert.Empty(t, subjects)
+
+	withDeleted, err := c.SubjectsIncludingDeleted()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"users-value"}, withDeleted)
+
+	all, err = c.PermanentlyDeleteSubject("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, all)
+
+	_, err = c.GetSchemaByID(2)
+	assert.True(t, schemaregistry.IsSchemaNotFound(err))
+	_, err = c.DeleteSubject("users-value")
+	assert.True(t, schemaregistry.IsSubjectNotFound(err))
+}
+
+func TestServer_SchemaTypes(t *testing.T) {
+	_, c := newTestClient(t)
+
+	types, err := c.SchemaTypes()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []schemaregistry.SchemaType{schemaregistry.Avro, schemaregistry.JSON, schemaregistry.Protobuf}, types)
+
+	proto := schemaregistry.Schema{Schema: `syntax = "proto3"; message User { string name = 1; }`, SchemaType: schemaregistry.Protobuf}
+	protoID, err := c.RegisterSchema("users-proto", proto)
+	assert.NoError(t, err)
+
+	jsonSchema := schemaregistry.Schema{Schema: `{"type":"string"}`, SchemaType: schemaregistry.JSON}
+	jsonID, err := c.RegisterSchema("users-json", jsonSchema)
+	assert.NoError(t, err)
+
+	// the same schema string with another type is another schema.
+	avroID, err := c.RegisterNewSchema("users-json", `{"type":"string"}`)
+	assert.NoError(t, err)
+	assert.NotEqual(t, jsonID, avroID)
+
+	s, err := c.GetSchemaDetailsByID(protoID)
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Schema{Schema: proto.Schema, ID: protoID, SchemaType: schemaregistry.Protobuf}, s)
+
+	s, err = c.GetSchemaDetailsByID(avroID)
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Avro, s.SchemaType)
+
+	latest, err := c.GetLatestSchema("users-proto")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Protobuf, latest.SchemaType)
+
+	ok, found, err := c.LookupSchema("users-json", jsonSchema)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, jsonID, found.ID)
+	assert.Equal(t, schemaregistry.JSON, found.SchemaType)
+
+	ok, err = c.CheckLatestCompatibility("users-proto", proto)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = c.RegisterSchema("users-xml", schemaregistry.Schema{Schema: "<xml/>", SchemaType: "XML"})
+	assert.Equal(t, invalidSchemaCode, err.(schemaregistry.ResourceError).ErrorCode)
+}
+
+func TestServer_References(t *testing.T) {
+	_, c := newTestClient(t)
+	refs := []schemaregistry.SchemaReference{{Name: "Address", Subject: "address-value", Version: 1}}
+	user := schemaregistry.Schema{Schema: `{"type":"record","name":"User","fields":[{"name":"address","type":"Address"}]}`, References: refs}
+
+	_, err := c.RegisterSchema("user-value", user)
+	assert.Equal(t, invalidSchemaCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	_, err = c.RegisterNewSchema("address-value", `{"type":"record","name":"Address","fields":[]}`)
+	assert.NoError(t, err)
+	userID, err := c.RegisterSchema("user-value", user)
+	assert.NoError(t, err)
+
+	s, err := c.GetLatestSchema("user-value")
+	assert.NoError(t, err)
+	assert.Equal(t, refs, s.References)
+
+	ids, err := c.ReferencedBy("address-value", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{userID}, ids)
+
+	_, err = c.DeleteSubject("address-value")
+	assert.Equal(t, referenceExistsCode, err.(schemaregistry.ResourceError).ErrorCode)
+
+	_, err = c.DeleteSubject("user-value")
+	assert.NoError(t, err)
+	_, err = c.DeleteSubject("address-value")
+	assert.NoError(t, err)
+}
+
+func TestServer_ClientMode(t *testing.T) {
+	_, c := newTestClient(t)
+
+	mode, err := c.SetMode(schemaregistry.Import, "")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Import, mode)
+
+	id, err := c.ImportSchema("users-value", schemaregistry.Schema{Schema: schemaV1, ID: 100, Version: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 100, id)
+
+	_, err = c.SetMode(schemaregistry.ReadOnly, "users-value")
+	assert.NoError(t, err)
+	mode, err = c.GetMode("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.ReadOnly, mode)
+
+	mode, err = c.DeleteMode("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.ReadOnly, mode)
+	mode, err = c.GetMode("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Mode(""), mode)
+	mode, err = c.GetEffectiveMode("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Import, mode)
+
+	_, err = c.SetMode(schemaregistry.ReadWrite, "")
+	assert.NoError(t, err)
+	_, err = c.SetMode(schemaregistry.Import, "")
+	assert.Error(t, err)
+	_, err = c.SetModeForced(schemaregistry.Import, "")
+	assert.NoError(t, err)
+}
+
+func TestServer_ClientConfig(t *testing.T) {
+	_, c := newTestClient(t)
+
+	cfg, err := c.SetConfigLevel(schemaregistry.None, "")
+	assert.NoError(t, err)
+	level, err := cfg.Level()
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.None, level)
+
+	cfg, err = c.GetConfig("orders-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Config{}, cfg)
+
+	cfg, err = c.GetEffectiveConfig("orders-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "NONE", cfg.CompatibilityLevel)
+
+	_, err = c.SetConfigLevel(schemaregistry.ForwardTransitive, "orders-value")
+	assert.NoError(t, err)
+	cfg, err = c.DeleteConfig("orders-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "FORWARD_TRANSITIVE", cfg.CompatibilityLevel)
+
+	_, err = c.DeleteConfig("orders-value")
+	assert.Error(t, err)
+
+	cfg, err = c.DeleteConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, "NONE", cfg.CompatibilityLevel)
+	cfg, err = c.GetConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, "BACKWARD", cfg.CompatibilityLevel)
+}
@@ -0,0 +1,193 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// These error codes are used by the schema registry to report a failure of its backend store,
+// the request can be retried once the registry recovers.
+const (
+	backendStoreErrorCode    = 50001
+	operationTimeoutCode     = 50002
+	requestForwardingErrCode = 50003
+)
+
+const retryAfterHeaderKey = "Retry-After"
+
+// RetryPolicy describes how the `Client` retries a failed request, see `WithRetry`.
+//
+// Requests are retried on connection errors, 5xx and 429 status codes
+// and on the schema registry's 50001-50003 error codes.
+// Only idempotent requests are retried: reads, lookups, compatibility checks
+// and configuration updates. Schema registrations are retried only when `RetryRegistrations` is true.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value less than 2 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff limits the time to wait between two attempts, zero means no limit.
+	MaxBackoff time.Duration
+	// Multiplier is the factor the backoff grows by after each attempt, defaults to 2.
+	Multiplier float64
+	// Jitter randomizes each backoff by up to the given fraction of it, e.g. 0.2 for ±20%.
+	Jitter float64
+	// RetryRegistrations enables retries of `RegisterNewSchema` requests as well.
+	RetryRegistrations bool
+}
+
+// DefaultRetryPolicy is a sane `RetryPolicy` to survive a rolling restart of the registry.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// WithRetry enables retries of failed requests based on the given policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = &policy
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+// allows reports whether a request with the given method and path may be retried.
+func (p *RetryPolicy) allows(method, path string) bool {
+	if p.maxAttempts() < 2 {
+		return false
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut:
+		return true
+	case http.MethodPost:
+		// POST /compatibility/subjects/(string: subject)/versions/(versionId: "latest" | int)
+		if strings.HasPrefix(path, "compatibility/") {
+			return true
+		}
+		// POST /subjects/(string: subject)/versions
+		if strings.HasSuffix(path, "/versions") {
+			return p.RetryRegistrations
+		}
+		// POST /subjects/(string: subject)
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the time to wait after the given failed attempt,
+// a valid `Retry-After` header of the response takes precedence over the policy.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get(retryAfterHeaderKey)); ok {
+			return d
+		}
+	}
+
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	d := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	if d < 0 {
+		return 0
+	}
+
+	return time.Duration(d)
+}
+
+// parseRetryAfter parses the value of a `Retry-After` header, either delay-seconds or an HTTP-date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// shouldRetry reports whether the failed attempt is worth to be retried.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if resp != nil && (resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests) {
+		return true
+	}
+
+	if resErr, ok := err.(ResourceError); ok {
+		switch resErr.ErrorCode {
+		case backendStoreErrorCode, operationTimeoutCode, requestForwardingErrCode, http.StatusTooManyRequests:
+			return true
+		}
+		return resErr.ErrorCode >= http.StatusInternalServerError && resErr.ErrorCode < 600
+	}
+
+	// no response at all, it's a connection error.
+	return resp == nil && isConnectionError(err)
+}
+
+// isConnectionError reports whether the error is raised by the transport layer, i.e. the registry is unreachable.
+func isConnectionError(err error) bool {
+	var (
+		urlErr *url.Error
+		netErr net.Error
+	)
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+// sleepContext waits for "d" or until the context is done, whichever happens first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
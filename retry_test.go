@@ -0,0 +1,152 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+}
+
+// flakyHTTPHandler fails the first "failures" requests with the given status and error code.
+func flakyHTTPHandler(t *testing.T, failures, status, errCode int, respBody interface{}) (D, *int) {
+	var calls int
+	d := D(func(req *http.Request) (*http.Response, error) {
+		calls++
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{contentTypeHeaderKey: []string{contentTypeJSON}},
+		}
+		body := respBody
+		if calls <= failures {
+			resp.StatusCode = status
+			body = ResourceError{ErrorCode: errCode, Message: "unavailable"}
+		}
+		bs, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(bs))
+		return resp, nil
+	})
+	return d, &calls
+}
+
+func TestRetry_PassedAfterServerErrors(t *testing.T) {
+	d, calls := flakyHTTPHandler(t, 2, http.StatusInternalServerError, backendStoreErrorCode, []string{"subject"})
+	c := &Client{baseURL: "http://" + testHost, client: d}
+	WithRetry(testRetryPolicy)(c)
+
+	subs, err := c.Subjects()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"subject"}, subs)
+	assert.Equal(t, 3, *calls)
+}
+
+func TestRetry_FailedMaxAttempts(t *testing.T) {
+	d, calls := flakyHTTPHandler(t, 5, http.StatusServiceUnavailable, http.StatusServiceUnavailable, []string{})
+	c := &Client{baseURL: "http://" + testHost, client: d}
+	WithRetry(testRetryPolicy)(c)
+
+	_, err := c.Subjects()
+	assert.Error(t, err)
+	assert.Equal(t, 3, *calls)
+}
+
+func TestRetry_NotRetriedClientErrors(t *testing.T) {
+	d, calls := flakyHTTPHandler(t, 1, http.StatusNotFound, subjectNotFoundCode, []int{})
+	c := &Client{baseURL: "http://" + testHost, client: d}
+	WithRetry(testRetryPolicy)(c)
+
+	_, err := c.Versions("subject")
+	assert.True(t, IsSubjectNotFound(err))
+	assert.Equal(t, 1, *calls)
+}
+
+func TestRetry_RegistrationsOnlyWhenEnabled(t *testing.T) {
+	d, calls := flakyHTTPHandler(t, 1, http.StatusInternalServerError, operationTimeoutCode, idOnlyJSON{ID: 1})
+	c := &Client{baseURL: "http://" + testHost, client: d}
+	WithRetry(testRetryPolicy)(c)
+
+	_, err := c.RegisterNewSchema("subject", `"string"`)
+	assert.Error(t, err)
+	assert.Equal(t, 1, *calls)
+
+	policy := testRetryPolicy
+	policy.RetryRegistrations = true
+	d, calls = flakyHTTPHandler(t, 1, http.StatusInternalServerError, operationTimeoutCode, idOnlyJSON{ID: 1})
+	c = &Client{baseURL: "http://" + testHost, client: d}
+	WithRetry(policy)(c)
+
+	id, err := c.RegisterNewSchema("subject", `"string"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, 2, *calls)
+}
+
+func TestRetry_ConnectionErrors(t *testing.T) {
+	var calls int
+	c := &Client{baseURL: "http://" + testHost, client: D(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: assert.AnError}
+	})}
+	WithRetry(testRetryPolicy)(c)
+
+	_, err := c.GetSchemaByID(1)
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_StopsOnContextDone(t *testing.T) {
+	d, calls := flakyHTTPHandler(t, 5, http.StatusServiceUnavailable, http.StatusServiceUnavailable, []string{})
+	c := &Client{baseURL: "http://" + testHost, client: d}
+	WithRetry(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})(c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := c.SubjectsContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, p.backoff(1, nil))
+	assert.Equal(t, 200*time.Millisecond, p.backoff(2, nil))
+	assert.Equal(t, 400*time.Millisecond, p.backoff(3, nil))
+	assert.Equal(t, time.Second, p.backoff(10, nil))
+
+	resp := &http.Response{Header: http.Header{retryAfterHeaderKey: []string{"3"}}}
+	assert.Equal(t, 3*time.Second, p.backoff(1, resp))
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.backoff(1, nil)
+		assert.True(t, d >= 50*time.Millisecond && d <= 150*time.Millisecond, d)
+	}
+}
+
+func TestRetryPolicyAllows(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 2}
+	assert.True(t, p.allows(http.MethodGet, "subjects"))
+	assert.True(t, p.allows(http.MethodPut, "config/subject"))
+	assert.True(t, p.allows(http.MethodPost, "subjects/subject"))
+	assert.True(t, p.allows(http.MethodPost, "compatibility/subjects/subject/versions/latest"))
+	assert.False(t, p.allows(http.MethodPost, "subjects/subject/versions"))
+	assert.False(t, p.allows(http.MethodDelete, "subjects/subject"))
+
+	var disabled *RetryPolicy
+	assert.False(t, disabled.allows(http.MethodGet, "subjects"))
+}
@@ -7,24 +7,59 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// importID and importVersion are the values of the "--id" and "--version" flags,
+// they register the schema with an explicit ID and version, which the IMPORT mode requires.
+var (
+	importID      int
+	importVersion int
+)
+
 var addCmd = &cobra.Command{
-	Use:          "add <subject>",
+	Use:          "add <subject> | --topic <topic>",
 	Short:        "registers the schema provided through stdin",
 	Long:         ``,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
+		if len(args) > 1 {
 			return fmt.Errorf("expected 1 argument")
 		}
-		id, err := assertClient().RegisterNewSchema(args[0], stdinToString())
+		if importVersion != 0 && importID == 0 {
+			return fmt.Errorf("--version requires --id")
+		}
+		schema, err := stdinToSchema()
+		if err != nil {
+			return err
+		}
+		subject, _, err := subjectArgs(args, schema)
+		if err != nil {
+			return err
+		}
+		client := assertClient()
+		register := client.RegisterSchema
+		if importID > 0 {
+			schema.ID, schema.Version = importID, importVersion
+			register = client.ImportSchema
+		}
+		id, err := register(subject, schema)
 		if err != nil {
 			return err
 		}
-		log.Printf("registered schema with id %d\n", id)
-		return nil
+		res := struct {
+			Subject string `json:"subject"`
+			ID      int    `json:"id"`
+		}{subject, id}
+		t := table{header: []string{"SUBJECT", "ID"}}
+		t.add(subject, id)
+		return printResult(res, t, func() {
+			log.Printf("registered schema with id %d\n", id)
+		})
 	},
 }
 
 func init() {
+	addSchemaFlags(addCmd)
+	addTopicFlags(addCmd)
+	addCmd.Flags().IntVar(&importID, "id", 0, "register with this ID, the subject must be in IMPORT mode")
+	addCmd.Flags().IntVar(&importVersion, "version", 0, "register with this version, requires --id")
 	RootCmd.AddCommand(addCmd)
 }
@@ -2,47 +2,91 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 
+	schemaregistry "github.com/bjornm82/schema-registry"
 	"github.com/spf13/cobra"
 )
 
+// exitIncompatible is the exit code of the "compatible" command when the schema is not compatible,
+// it tells an incompatible schema apart from a failed check.
+const exitIncompatible = 2
+
+// allVersions is the value of the "--all" flag of the "compatible" command.
+var allVersions bool
+
 // compatible can handle two argument styles: <subj ver> or <subj>
 var compatibleCmd = &cobra.Command{
-	Use:   "compatible <subject> [version]",
+	Use:   "compatible (<subject> | --topic <topic>) [version]",
 	Short: "tests compatibility between a schema from stdin and a given subject",
 	Long: `The compatibility level of the subject is used for this check.
 If it has never been changed, the global compatibility level applies.
-If no schema version is specified, the latest version is tested.
+If no schema version is specified, the latest version is tested, with --all
+the versions the compatibility level requires are tested.
+The reasons of an incompatibility are printed and the command exits with code 2.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) < 1 || len(args) > 2 {
+		if len(args) > 2 {
 			return fmt.Errorf("expected 1 to 2 arguments")
 		}
-		var iscompat bool
-		var err error
-		switch len(args) {
-		case 1:
-			iscompat, err = assertClient().IsLatestSchemaCompatible(args[0], stdinToString())
-		case 2:
-			ver, err := strconv.Atoi(args[1])
-			if err != nil {
-				return fmt.Errorf("2nd argument must be a version number")
+		schema, err := stdinToSchema()
+		if err != nil {
+			return err
+		}
+		subject, rest, err := subjectArgs(args, schema)
+		if err != nil {
+			return err
+		}
+		var res schemaregistry.CompatibilityResult
+		switch {
+		case len(rest) > 1:
+			return fmt.Errorf("expected 1 to 2 arguments")
+		case len(rest) == 1 && allVersions:
+			return fmt.Errorf("a version can't be used with --all")
+		case len(rest) == 1:
+			ver, convErr := strconv.Atoi(rest[0])
+			if convErr != nil {
+				return fmt.Errorf("the version must be a number")
 			}
-			iscompat, err = assertClient().IsSchemaCompatible(args[0], stdinToString(), ver)
+			res, err = assertClient().CheckCompatibilityVerbose(subject, schema, ver)
+		case allVersions:
+			res, err = assertClient().CheckAllVersionsCompatibility(subject, schema)
+		default:
+			res, err = assertClient().CheckLatestCompatibilityVerbose(subject, schema)
 		}
 		if err != nil {
 			return err
 		}
-		if iscompat {
-			fmt.Println("the provided schema is compatible")
-		} else {
-			err = fmt.Errorf("the provided schema is not compatible")
+		t := table{header: []string{"COMPATIBLE", "MESSAGE"}}
+		if len(res.Messages) == 0 {
+			t.add(res.IsCompatible, "")
+		}
+		for _, msg := range res.Messages {
+			t.add(res.IsCompatible, msg)
+		}
+		err = printResult(res, t, func() {
+			if res.IsCompatible {
+				fmt.Println("the provided schema is compatible")
+				return
+			}
+
+			fmt.Println("the provided schema is not compatible")
+			for _, msg := range res.Messages {
+				fmt.Printf("  - %s\n", msg)
+			}
+		})
+		if err != nil || res.IsCompatible {
+			return err
 		}
-		return err
+		os.Exit(exitIncompatible)
+		return nil
 	},
 }
 
 func init() {
+	addSchemaFlags(compatibleCmd)
+	addTopicFlags(compatibleCmd)
+	compatibleCmd.Flags().BoolVar(&allVersions, "all", false, "test against all the versions the compatibility level requires")
 	RootCmd.AddCommand(compatibleCmd)
 }
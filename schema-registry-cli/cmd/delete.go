@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <subject>",
+	Short: "deletes a subject and all its versions",
+	Long: `Soft-deletes the subject, its versions can still be listed with --deleted.
+With --permanent a soft-deleted subject is deleted for good.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected 1 argument")
+		}
+		if !confirm(fmt.Sprintf("delete subject %s%s?", args[0], permanentSuffix())) {
+			return nil
+		}
+		client := assertClient()
+		deleteSubject := client.DeleteSubject
+		if permanent {
+			deleteSubject = client.PermanentlyDeleteSubject
+		}
+		vers, err := deleteSubject(args[0])
+		if err != nil {
+			return err
+		}
+		res := struct {
+			Subject  string `json:"subject"`
+			Versions []int  `json:"versions"`
+		}{args[0], vers}
+		t := table{header: []string{"SUBJECT", "VERSION"}}
+		for _, v := range vers {
+			t.add(args[0], v)
+		}
+		return printResult(res, t, func() {
+			fmt.Printf("deleted versions: %v\n", vers)
+		})
+	},
+}
+
+func init() {
+	addDeleteFlags(deleteCmd)
+	RootCmd.AddCommand(deleteCmd)
+}
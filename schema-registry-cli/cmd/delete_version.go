@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var deleteVersionCmd = &cobra.Command{
+	Use:   "delete-version <subject> <version>",
+	Short: "deletes a single version of a subject",
+	Long: `Soft-deletes the version of the subject, it can still be listed with --deleted.
+With --permanent a soft-deleted version is deleted for good.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("expected 2 arguments")
+		}
+		ver, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("2nd argument must be a version number")
+		}
+		if !confirm(fmt.Sprintf("delete version %d of subject %s%s?", ver, args[0], permanentSuffix())) {
+			return nil
+		}
+		client := assertClient()
+		deleteVersion := client.DeleteSchemaVersion
+		if permanent {
+			deleteVersion = client.PermanentlyDeleteSchemaVersion
+		}
+		deleted, err := deleteVersion(args[0], ver)
+		if err != nil {
+			return err
+		}
+		res := struct {
+			Subject string `json:"subject"`
+			Version int    `json:"version"`
+		}{args[0], deleted}
+		t := table{header: []string{"SUBJECT", "VERSION"}}
+		t.add(args[0], deleted)
+		return printResult(res, t, func() {
+			fmt.Printf("deleted version: %d\n", deleted)
+		})
+	},
+}
+
+func init() {
+	addDeleteFlags(deleteVersionCmd)
+	RootCmd.AddCommand(deleteVersionCmd)
+}
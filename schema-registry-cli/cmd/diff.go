@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// diff can handle two argument styles: <subj ver ver> or <subj ver> with the new schema through stdin
+var diffCmd = &cobra.Command{
+	Use:   "diff <subject> <version> [<version>]",
+	Short: "shows the changes between two versions of a subject's schema",
+	Long: `The changes are the added, removed and changed fields, with their types and
+defaults, and the added and removed enum symbols.
+When a single version is given, it's compared with the schema provided through stdin.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 || len(args) > 3 {
+			return fmt.Errorf("expected 2 to 3 arguments")
+		}
+		var versions []int
+		for _, arg := range args[1:] {
+			ver, err := strconv.Atoi(arg)
+			if err != nil {
+				return fmt.Errorf("%q is not a version number", arg)
+			}
+			versions = append(versions, ver)
+		}
+
+		cl := assertClient()
+		old, err := cl.GetSchemaBySubject(args[0], versions[0])
+		if err != nil {
+			return err
+		}
+		var new schemaregistry.Schema
+		if len(versions) == 2 {
+			new, err = cl.GetSchemaBySubject(args[0], versions[1])
+		} else {
+			new, err = stdinToSchema()
+		}
+		if err != nil {
+			return err
+		}
+
+		changes, err := schemaregistry.DiffReferencedSchemas(cl, old, new)
+		if err != nil {
+			return err
+		}
+		if changes == nil {
+			// an empty list, not null, in the json and yaml documents.
+			changes = []schemaregistry.SchemaChange{}
+		}
+		t := table{header: []string{"KIND", "PATH", "OLD", "NEW"}}
+		for _, c := range changes {
+			t.add(c.Kind, c.Path, c.Old, c.New)
+		}
+		return printResult(changes, t, func() {
+			printChanges(changes)
+		})
+	},
+}
+
+func printChanges(changes []schemaregistry.SchemaChange) {
+	if len(changes) == 0 {
+		fmt.Println("no changes")
+		return
+	}
+	for _, c := range changes {
+		switch c.Kind {
+		case schemaregistry.FieldAdded, schemaregistry.SymbolAdded:
+			color.Green("+ %s", c)
+		case schemaregistry.FieldRemoved, schemaregistry.SymbolRemoved:
+			color.Red("- %s", c)
+		default:
+			color.Yellow("~ %s", c)
+		}
+	}
+}
+
+func init() {
+	addSchemaFlags(diffCmd)
+	RootCmd.AddCommand(diffCmd)
+}
@@ -3,30 +3,93 @@ package cmd
 import (
 	"fmt"
 
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/bjornm82/schema-registry/avro"
 	"github.com/spf13/cobra"
 )
 
 var existsCmd = &cobra.Command{
-	Use:   "exists <subject>",
+	Use:   "exists <subject> | --topic <topic>",
 	Short: "checks if the schema provided through stdin exists for the subject",
 	Long:  ``,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
+		if len(args) > 1 {
 			return fmt.Errorf("expected 1 argument")
 		}
-		isreg, sch, err := assertClient().IsRegistered(args[0], stdinToString())
+		schema, err := stdinToSchema()
 		if err != nil {
 			return err
 		}
-		fmt.Printf("exists: %v\n", isreg)
-		if isreg {
-			fmt.Printf("id: %d\n", sch.ID)
-			fmt.Printf("version: %d\n", sch.Version)
+		subject, _, err := subjectArgs(args, schema)
+		if err != nil {
+			return err
 		}
-		return nil
+		client := assertClient()
+		isreg, sch, err := client.LookupSchema(subject, schema)
+		if err != nil {
+			return err
+		}
+		if !isreg {
+			isreg, sch, err = findCanonical(client, subject, schema)
+			if err != nil {
+				return err
+			}
+		}
+		res := struct {
+			Exists  bool   `json:"exists"`
+			Subject string `json:"subject"`
+			ID      int    `json:"id,omitempty"`
+			Version int    `json:"version,omitempty"`
+		}{isreg, subject, sch.ID, sch.Version}
+		t := table{header: []string{"SUBJECT", "EXISTS", "ID", "VERSION"}}
+		t.add(subject, isreg, sch.ID, sch.Version)
+		return printResult(res, t, func() {
+			fmt.Printf("exists: %v\n", isreg)
+			if isreg {
+				fmt.Printf("id: %d\n", sch.ID)
+				fmt.Printf("version: %d\n", sch.Version)
+			}
+		})
 	},
 }
 
+// findCanonical looks for a version of the subject with the same Avro canonical form as the schema,
+// it matches the schemas the registry doesn't, e.g. because of a different order of the attributes.
+func findCanonical(client *schemaregistry.Client, subject string, schema schemaregistry.Schema) (bool, schemaregistry.Schema, error) {
+	if schema.SchemaType != "" && schema.SchemaType != schemaregistry.Avro || len(schema.References) > 0 {
+		return false, schemaregistry.Schema{}, nil
+	}
+	parsed, err := avro.Parse(schema.Schema)
+	if err != nil {
+		// the registry looked it up already, a schema this package can't parse is just not found.
+		return false, schemaregistry.Schema{}, nil
+	}
+
+	versions, err := client.Versions(subject)
+	if err != nil {
+		if schemaregistry.IsSubjectNotFound(err) {
+			return false, schemaregistry.Schema{}, nil
+		}
+		return false, schemaregistry.Schema{}, err
+	}
+	for _, version := range versions {
+		registered, err := client.GetSchemaBySubject(subject, version)
+		if err != nil {
+			return false, schemaregistry.Schema{}, err
+		}
+		if registered.SchemaType != schemaregistry.Avro || len(registered.References) > 0 {
+			continue
+		}
+		other, err := avro.Parse(registered.Schema)
+		if err == nil && other.CanonicalForm() == parsed.CanonicalForm() {
+			return true, registered, nil
+		}
+	}
+	return false, schemaregistry.Schema{}, nil
+}
+
 func init() {
+	addSchemaFlags(existsCmd)
+	addTopicFlags(existsCmd)
 	RootCmd.AddCommand(existsCmd)
 }
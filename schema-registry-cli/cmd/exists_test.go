@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCanonical(t *testing.T) {
+	_, c := newTestClient(t)
+	_, err := c.RegisterNewSchema("users-value", userV1)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		schema string
+		found  bool
+	}{
+		{"reordered attributes", `{"name":"User","type":"record","fields":[{"type":"string","name":"name"}]}`, true},
+		{"other schema", userV2, false},
+		{"unparsable schema", `{"type":"record","name":"User","fields":[{"name":"name","type":"Unknown"}]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, registered, err := findCanonical(c, "users-value", schemaregistry.Schema{Schema: tt.schema})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.found, found)
+			if tt.found {
+				assert.Equal(t, 1, registered.Version)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "writes a snapshot of the registry as a JSON archive",
+	Long: `The archive holds every subject with its versions, IDs and references, and the
+global and subject compatibility levels and modes. It's written to stdout when no
+file is given. Use "import" to restore it.
+`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("expected at most 1 argument")
+		}
+		archive, err := schemaregistry.ExportRegistry(assertClient())
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(archive, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+
+		if len(args) == 0 || args[0] == "-" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := ioutil.WriteFile(args[0], data, 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "exported %d subjects to %s\n", len(archive.Subjects), args[0])
+		return nil
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "restores a JSON archive written by export",
+	Long: `The schemas are imported with their IDs and versions, so the registry must have
+no schemas: it's switched to IMPORT mode and then to the archived modes. The archive
+is read from stdin when no file is given.
+`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("expected at most 1 argument")
+		}
+		var data []byte
+		if len(args) == 0 || args[0] == "-" {
+			data = []byte(stdinToString())
+		} else {
+			var err error
+			if data, err = ioutil.ReadFile(args[0]); err != nil {
+				return err
+			}
+		}
+
+		var archive schemaregistry.Archive
+		if err := json.Unmarshal(data, &archive); err != nil {
+			return fmt.Errorf("invalid archive: %v", err)
+		}
+		if err := schemaregistry.ImportRegistry(assertClient(), archive); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "imported %d subjects\n", len(archive.Subjects))
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(importCmd)
+}
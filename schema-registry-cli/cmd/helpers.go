@@ -6,8 +6,11 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/hokaccha/go-prettyjson"
+	"github.com/spf13/cobra"
 
 	schemaregistry "github.com/bjornm82/schema-registry"
 	"github.com/spf13/viper"
@@ -21,27 +24,163 @@ func stdinToString() string {
 	return string(bs)
 }
 
-func printSchema(sch schemaregistry.Schema) {
-	log.Printf("version: %d\n", sch.Version)
-	log.Printf("id: %d\n", sch.ID)
+// schemaType and schemaReferences are the values of the "--type" and "--reference" flags
+// of the commands which read a schema from stdin.
+var (
+	schemaType       string
+	schemaReferences []string
+)
+
+func addSchemaFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&schemaType, "type", "t", string(schemaregistry.Avro), "schema type: AVRO, JSON or PROTOBUF")
+	cmd.Flags().StringArrayVarP(&schemaReferences, "reference", "r", nil, "schema reference as name=subject:version, can be repeated")
+}
+
+// topic, isKey and strategy are the values of the "--topic", "--key" and "--strategy" flags,
+// they derive the subject from a topic instead of taking it as the first argument.
+var (
+	topic    string
+	isKey    bool
+	strategy string
+)
+
+func addTopicFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&topic, "topic", "", "derive the subject from this topic instead of passing it")
+	cmd.Flags().BoolVar(&isKey, "key", false, "the schema is the one of the topic's keys, not of its values")
+	cmd.Flags().StringVar(&strategy, "strategy", "topic", "subject name strategy for --topic: topic, record or topic-record")
+}
+
+// subjectArgs returns the subject and the remaining arguments, the subject is the first argument
+// unless the "--topic" flag is set.
+func subjectArgs(args []string, schema schemaregistry.Schema) (string, []string, error) {
+	if topic == "" {
+		if len(args) == 0 {
+			return "", nil, fmt.Errorf("expected a subject, or the --topic flag")
+		}
+		return args[0], args[1:], nil
+	}
+
+	var s schemaregistry.SubjectNameStrategy
+	switch strategy {
+	case "topic":
+		s = schemaregistry.TopicNameStrategy
+	case "record":
+		s = schemaregistry.RecordNameStrategy
+	case "topic-record":
+		s = schemaregistry.TopicRecordNameStrategy
+	default:
+		return "", nil, fmt.Errorf("unknown strategy %q, expected topic, record or topic-record", strategy)
+	}
+	subject, err := s.SubjectName(topic, isKey, schema)
+	return subject, args, err
+}
 
-	pretty, err := prettyjson.Format([]byte(sch.Schema))
+// parseReference parses a "--reference" flag value of the form name=subject:version.
+func parseReference(s string) (schemaregistry.SchemaReference, error) {
+	eq := strings.Index(s, "=")
+	colon := strings.LastIndex(s, ":")
+	if eq <= 0 || colon < eq+2 {
+		return schemaregistry.SchemaReference{}, fmt.Errorf("invalid reference %q, expected name=subject:version", s)
+	}
+	version, err := strconv.Atoi(s[colon+1:])
 	if err != nil {
-		fmt.Println(sch.Schema) //isn't a json object, which is legal
-		return
+		return schemaregistry.SchemaReference{}, fmt.Errorf("invalid reference %q, version must be a number", s)
+	}
+	return schemaregistry.SchemaReference{Name: s[:eq], Subject: s[eq+1 : colon], Version: version}, nil
+}
+
+// stdinToSchema reads the schema from stdin, its type and references are the ones of the
+// "--type" and "--reference" flags.
+func stdinToSchema() (schemaregistry.Schema, error) {
+	st := schemaregistry.SchemaType(strings.ToUpper(schemaType))
+	switch st {
+	case schemaregistry.Avro, schemaregistry.JSON, schemaregistry.Protobuf:
+	default:
+		return schemaregistry.Schema{}, fmt.Errorf("unknown schema type %q, expected AVRO, JSON or PROTOBUF", schemaType)
+	}
+	var refs []schemaregistry.SchemaReference
+	for _, r := range schemaReferences {
+		ref, err := parseReference(r)
+		if err != nil {
+			return schemaregistry.Schema{}, err
+		}
+		refs = append(refs, ref)
+	}
+	return schemaregistry.Schema{Schema: stdinToString(), SchemaType: st, References: refs}, nil
+}
+
+// permanent and assumeYes are the values of the "--permanent" and "--yes" flags of the delete commands.
+var (
+	permanent bool
+	assumeYes bool
+)
+
+func addDeleteFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&permanent, "permanent", false, "delete for good, what is deleted must be soft-deleted first")
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "don't ask for confirmation")
+}
+
+// includeDeleted is the value of the "--deleted" flag of the listing commands.
+var includeDeleted bool
+
+func addDeletedFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&includeDeleted, "deleted", false, "include the soft-deleted ones")
+}
+
+func permanentSuffix() string {
+	if permanent {
+		return " permanently"
+	}
+	return ""
+}
+
+// confirm asks the question on stderr, so it's not mixed with the output, and reports whether
+// it's answered with yes, it's always true when the "--yes" flag is set.
+func confirm(question string) bool {
+	if assumeYes {
+		return true
 	}
-	os.Stdout.Write(pretty)
-	os.Stdout.WriteString("\n")
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+func printSchema(sch schemaregistry.Schema) error {
+	t := table{header: []string{"SUBJECT", "VERSION", "ID", "TYPE"}}
+	t.add(sch.Subject, sch.Version, sch.ID, sch.SchemaType)
+	return printResult(sch, t, func() {
+		log.Printf("version: %d\n", sch.Version)
+		log.Printf("id: %d\n", sch.ID)
+
+		pretty, err := prettyjson.Format([]byte(sch.Schema))
+		if err != nil {
+			fmt.Println(sch.Schema) //isn't a json object, which is legal
+			return
+		}
+		os.Stdout.Write(pretty)
+		os.Stdout.WriteString("\n")
+	})
 }
 
 func getByID(id int) error {
 	cl := assertClient()
-	sch, err := cl.GetSchemaByID(id)
+	sch, err := cl.GetSchemaDetailsByID(id)
 	if err != nil {
 		return err
 	}
-	fmt.Println(sch)
-	return nil
+	t := table{header: []string{"ID", "TYPE"}}
+	t.add(sch.ID, sch.SchemaType)
+	return printResult(sch, t, func() {
+		fmt.Println(sch.Schema)
+	})
 }
 
 func getLatestBySubject(subj string) error {
@@ -50,8 +189,7 @@ func getLatestBySubject(subj string) error {
 	if err != nil {
 		return err
 	}
-	printSchema(sch)
-	return nil
+	return printSchema(sch)
 }
 
 func getBySubjectVersion(subj string, ver int) error {
@@ -60,18 +198,27 @@ func getBySubjectVersion(subj string, ver int) error {
 	if err != nil {
 		return err
 	}
-	printSchema(sch)
-	return nil
+	return printSchema(sch)
 }
 
-func printConfig(cfg schemaregistry.Config, subj string) {
+func printConfig(cfg schemaregistry.Config, subj string) error {
+	res := struct {
+		Subject            string `json:"subject,omitempty"`
+		CompatibilityLevel string `json:"compatibilityLevel,omitempty"`
+	}{Subject: subj}
+	level := "not defined, using global"
+	if cl, err := cfg.Level(); err == nil {
+		level = cl.String()
+		res.CompatibilityLevel = level
+	}
 	if subj == "" {
 		subj = "global"
 	}
-	if cfg.CompatibilityLevel == "" {
-		cfg.CompatibilityLevel = "not defined, using global"
-	}
-	fmt.Printf("%s compatibility-level: %s\n", subj, cfg.CompatibilityLevel)
+	t := table{header: []string{"SUBJECT", "COMPATIBILITY-LEVEL"}}
+	t.add(subj, level)
+	return printResult(res, t, func() {
+		fmt.Printf("%s compatibility-level: %s\n", subj, level)
+	})
 }
 
 func getConfig(subj string) error {
@@ -80,16 +227,24 @@ func getConfig(subj string) error {
 	if err != nil {
 		return err
 	}
-	printConfig(cfg, subj)
-	return nil
+	return printConfig(cfg, subj)
+}
+
+// clientOptions returns the client options configured through flags and environment variables.
+func clientOptions() []schemaregistry.Option {
+	p := profile{
+		BasicAuthUser:     viper.GetString("basic_auth_user"),
+		BasicAuthPassword: viper.GetString("basic_auth_password"),
+		BearerToken:       viper.GetString("bearer_token"),
+		CACert:            viper.GetString("ca_cert"),
+		ClientCert:        viper.GetString("client_cert"),
+		ClientKey:         viper.GetString("client_key"),
+	}
+	return p.clientOptions()
 }
 
 func assertClient() *schemaregistry.Client {
-	c, err := schemaregistry.NewClient(
-		viper.GetString("host"),
-		viper.GetInt("port"),
-		viper.GetBool("useSSL"),
-	)
+	c, err := schemaregistry.NewClientFromURL(viper.GetString("url"), clientOptions()...)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(-1)
@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"gopkg.in/yaml.v2"
+)
+
+// manifest maps the subjects to their schema files, it's read from the YAML files of a directory, e.g.:
+//
+//	subjects:
+//	  users-value:
+//	    file: users.avsc
+//	    compatibility: FULL
+//	  orders-value:
+//	    file: orders.avsc
+//	    type: AVRO
+//	    references:
+//	      - name: com.example.User
+//	        subject: users-value
+//	        version: 1
+type manifest struct {
+	Subjects map[string]manifestSubject `yaml:"subjects"`
+}
+
+type manifestSubject struct {
+	// File is the schema file, relative to the manifest file.
+	File string `yaml:"file"`
+	// Type is the schema type, AVRO by default.
+	Type string `yaml:"type,omitempty"`
+	// Compatibility is the compatibility level of the subject, it's left as is when empty.
+	Compatibility string                           `yaml:"compatibility,omitempty"`
+	References    []schemaregistry.SchemaReference `yaml:"references,omitempty"`
+}
+
+// desiredSubject is a subject of the manifest with its schema read.
+type desiredSubject struct {
+	name   string
+	file   string
+	schema schemaregistry.Schema
+	// level is nil when the manifest doesn't set it.
+	level *schemaregistry.CompatibilityLevel
+}
+
+// readManifest reads the subjects of the YAML files of the directory,
+// the referenced subjects come before the ones referring to them.
+func readManifest(dir string) ([]desiredSubject, error) {
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no manifest in %s, expected .yaml or .yml files", dir)
+	}
+
+	subjects := make(map[string]desiredSubject)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var m manifest
+		if err := yaml.UnmarshalStrict(data, &m); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+
+		for name, s := range m.Subjects {
+			if _, ok := subjects[name]; ok {
+				return nil, fmt.Errorf("%s: subject %s is defined twice", file, name)
+			}
+			desired, err := s.desired(name, filepath.Dir(file))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", file, err)
+			}
+			subjects[name] = desired
+		}
+	}
+	return sortByReferences(subjects)
+}
+
+func (s manifestSubject) desired(name, dir string) (desiredSubject, error) {
+	if s.File == "" {
+		return desiredSubject{}, fmt.Errorf("subject %s has no file", name)
+	}
+	file := filepath.Join(dir, s.File)
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return desiredSubject{}, err
+	}
+
+	st := schemaregistry.Avro
+	if s.Type != "" {
+		st = schemaregistry.SchemaType(strings.ToUpper(s.Type))
+	}
+	desired := desiredSubject{
+		name:   name,
+		file:   file,
+		schema: schemaregistry.Schema{Schema: string(data), SchemaType: st, References: s.References},
+	}
+	if s.Compatibility != "" {
+		level, err := schemaregistry.ParseCompatibilityLevel(s.Compatibility)
+		if err != nil {
+			return desiredSubject{}, fmt.Errorf("subject %s: %v", name, err)
+		}
+		desired.level = &level
+	}
+	return desired, nil
+}
+
+// sortByReferences sorts the subjects by name, the ones referenced by other subjects of the manifest first.
+func sortByReferences(subjects map[string]desiredSubject) ([]desiredSubject, error) {
+	names := make([]string, 0, len(subjects))
+	for name := range subjects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	sorted := make([]desiredSubject, 0, len(subjects))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("subject %s is part of a reference cycle", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, ref := range subjects[name].schema.References {
+			if _, ok := subjects[ref.Subject]; ok {
+				if err := visit(ref.Subject); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+		sorted = append(sorted, subjects[name])
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// The actions of a plan.
+const (
+	actionCreate        = "create"
+	actionRegister      = "register"
+	actionCompatibility = "compatibility"
+	actionIncompatible  = "incompatible"
+)
+
+// change is a step of a plan.
+type change struct {
+	Subject string `json:"subject"`
+	Action  string `json:"action"`
+	File    string `json:"file,omitempty"`
+	// From and To are the compatibility levels of a compatibility change.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Messages are the reasons of an incompatibility.
+	Messages []string `json:"messages,omitempty"`
+	// Recheck is set when the schema is checked under the planned compatibility level when it's applied.
+	Recheck bool `json:"recheck,omitempty"`
+
+	schema schemaregistry.Schema
+	level  schemaregistry.CompatibilityLevel
+}
+
+// makePlan compares the subjects with the registry, it returns the changes to apply in order:
+// the compatibility level of a subject changes before its schema is registered, and the schema
+// is checked under the planned level.
+func makePlan(client *schemaregistry.Client, subjects []desiredSubject) ([]change, error) {
+	changes := []change{}
+	// pending are the subjects which get a new version, the schemas referring to them can't be checked yet.
+	pending := make(map[string]bool)
+
+	for _, s := range subjects {
+		// planned is the level the plan sets, nil when it doesn't change.
+		var planned *schemaregistry.CompatibilityLevel
+		if s.level != nil {
+			cfg, err := client.GetConfig(s.name)
+			if err != nil {
+				return nil, err
+			}
+			current, err := cfg.Level()
+			if err != nil || current != *s.level {
+				from := "global"
+				if err == nil {
+					from = current.String()
+				}
+				changes = append(changes, change{Subject: s.name, Action: actionCompatibility, From: from, To: s.level.String(), level: *s.level})
+				planned = s.level
+			}
+		}
+
+		c, err := planSchema(client, s, planned, pending)
+		if err != nil {
+			return nil, fmt.Errorf("subject %s: %v", s.name, err)
+		}
+		if c != nil {
+			changes = append(changes, *c)
+			pending[s.name] = true
+		}
+	}
+	return changes, nil
+}
+
+// planSchema returns the change of the schema of the subject, nil when it's registered already.
+func planSchema(client *schemaregistry.Client, s desiredSubject, planned *schemaregistry.CompatibilityLevel, pending map[string]bool) (*change, error) {
+	c := &change{Subject: s.name, File: s.file, schema: s.schema}
+
+	versions, err := client.Versions(s.name)
+	if err != nil {
+		if !schemaregistry.IsSubjectNotFound(err) {
+			return nil, err
+		}
+		c.Action = actionCreate
+		return c, nil
+	}
+
+	for _, ref := range s.schema.References {
+		if pending[ref.Subject] {
+			// checked by the registry when it's applied.
+			c.Action = actionRegister
+			return c, nil
+		}
+	}
+
+	found, _, err := client.LookupSchema(s.name, s.schema)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return nil, nil
+	}
+
+	var res schemaregistry.CompatibilityResult
+	if planned != nil {
+		res, c.Recheck, err = checkPlannedLevel(client, s, *planned, versions)
+	} else {
+		res, err = client.CheckLatestCompatibilityVerbose(s.name, s.schema)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Action = actionRegister
+	if c.Recheck {
+		c.Messages = []string{fmt.Sprintf("checked under %s when applied", *planned)}
+		return c, nil
+	}
+	if !res.IsCompatible {
+		c.Action = actionIncompatible
+		c.Messages = res.Messages
+	}
+	return c, nil
+}
+
+// checkPlannedLevel checks the schema under the level the plan sets, which the registry doesn't use yet.
+// An Avro schema without references is checked locally against the versions of the subject, the other
+// ones are left to be checked when the plan is applied: recheck is true then.
+func checkPlannedLevel(client *schemaregistry.Client, s desiredSubject, level schemaregistry.CompatibilityLevel, versions []int) (res schemaregistry.CompatibilityResult, recheck bool, err error) {
+	if s.schema.SchemaType != schemaregistry.Avro || len(s.schema.References) > 0 {
+		return res, true, nil
+	}
+
+	sort.Ints(versions)
+	previous := make([]string, 0, len(versions))
+	for _, version := range versions {
+		registered, err := client.GetSchemaBySubject(s.name, version)
+		if err != nil {
+			return res, false, err
+		}
+		if registered.SchemaType != schemaregistry.Avro || len(registered.References) > 0 {
+			return res, true, nil
+		}
+		previous = append(previous, registered.Schema)
+	}
+	res, err = schemaregistry.CheckAvroCompatibility(level, s.schema.Schema, previous...)
+	return res, false, err
+}
@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/bjornm82/schema-registry/registrytest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	userV1 = `{"type":"record","name":"User","fields":[{"name":"name","type":"string"}]}`
+	// userV2 adds a field with a default, it's backward and forward compatible with userV1.
+	userV2 = `{"type":"record","name":"User","fields":[{"name":"name","type":"string"},{"name":"age","type":"int","default":0}]}`
+	// userV3 adds a field without a default, only forward compatible with userV1.
+	userV3  = `{"type":"record","name":"User","fields":[{"name":"name","type":"string"},{"name":"email","type":"string"}]}`
+	address = `{"type":"record","name":"Address","fields":[{"name":"city","type":"string"}]}`
+	order   = `{"type":"record","name":"Order","fields":[{"name":"address","type":"Address"}]}`
+)
+
+// writeFiles writes the files, by name, in a new directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func newTestClient(t *testing.T) (*registrytest.Server, *schemaregistry.Client) {
+	srv := registrytest.NewServer()
+	t.Cleanup(srv.Close)
+
+	c, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, c
+}
+
+func TestReadManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		subjects []string
+		err      string
+	}{
+		{
+			name: "sorted by name",
+			files: map[string]string{
+				"a.yaml":       "subjects:\n  users-value:\n    file: user.avsc\n",
+				"b.yml":        "subjects:\n  address-value:\n    file: address.avsc\n",
+				"user.avsc":    userV1,
+				"address.avsc": address,
+			},
+			subjects: []string{"address-value", "users-value"},
+		},
+		{
+			name: "referenced subjects first",
+			files: map[string]string{
+				"subjects.yaml": `subjects:
+  a-orders-value:
+    file: order.avsc
+    references:
+      - name: Address
+        subject: z-address-value
+        version: 1
+  z-address-value:
+    file: address.avsc
+`,
+				"order.avsc":   order,
+				"address.avsc": address,
+			},
+			subjects: []string{"z-address-value", "a-orders-value"},
+		},
+		{
+			name:  "no manifest",
+			files: map[string]string{"user.avsc": userV1},
+			err:   "no manifest",
+		},
+		{
+			name: "unknown key",
+			files: map[string]string{
+				"subjects.yaml": "subjects:\n  users-value:\n    file: user.avsc\n    compatibilty: FULL\n",
+				"user.avsc":     userV1,
+			},
+			err: "compatibilty",
+		},
+		{
+			name: "subject defined twice",
+			files: map[string]string{
+				"a.yaml":    "subjects:\n  users-value:\n    file: user.avsc\n",
+				"b.yaml":    "subjects:\n  users-value:\n    file: user.avsc\n",
+				"user.avsc": userV1,
+			},
+			err: "subject users-value is defined twice",
+		},
+		{
+			name:  "missing file",
+			files: map[string]string{"subjects.yaml": "subjects:\n  users-value:\n    file: user.avsc\n"},
+			err:   "user.avsc",
+		},
+		{
+			name:  "no file",
+			files: map[string]string{"subjects.yaml": "subjects:\n  users-value:\n    compatibility: FULL\n"},
+			err:   "subject users-value has no file",
+		},
+		{
+			name: "invalid compatibility",
+			files: map[string]string{
+				"subjects.yaml": "subjects:\n  users-value:\n    file: user.avsc\n    compatibility: SOME\n",
+				"user.avsc":     userV1,
+			},
+			err: "subject users-value",
+		},
+		{
+			name: "reference cycle",
+			files: map[string]string{
+				"subjects.yaml": `subjects:
+  a-value:
+    file: a.avsc
+    references: [{name: B, subject: b-value, version: 1}]
+  b-value:
+    file: b.avsc
+    references: [{name: A, subject: a-value, version: 1}]
+`,
+				"a.avsc": address,
+				"b.avsc": address,
+			},
+			err: "part of a reference cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subjects, err := readManifest(writeFiles(t, tt.files))
+			if tt.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			var names []string
+			for _, s := range subjects {
+				names = append(names, s.name)
+			}
+			assert.Equal(t, tt.subjects, names)
+		})
+	}
+}
+
+func TestMakePlan(t *testing.T) {
+	full := schemaregistry.Full
+	backward := schemaregistry.Backward
+	avro := func(schema string) schemaregistry.Schema {
+		return schemaregistry.Schema{Schema: schema, SchemaType: schemaregistry.Avro}
+	}
+
+	tests := []struct {
+		name string
+		// registered are the schemas of the "users-value" subject, under the global BACKWARD level.
+		registered []string
+		// level is the level of the subject, it uses the global one when it's nil.
+		level        *schemaregistry.CompatibilityLevel
+		incompatible bool
+		desired      desiredSubject
+		actions      []string
+		recheck      bool
+	}{
+		{
+			name:    "new subject",
+			desired: desiredSubject{name: "users-value", schema: avro(userV1)},
+			actions: []string{actionCreate},
+		},
+		{
+			name:       "registered schema",
+			registered: []string{userV1},
+			desired:    desiredSubject{name: "users-value", schema: avro(userV1)},
+		},
+		{
+			name:       "compatible schema",
+			registered: []string{userV1},
+			desired:    desiredSubject{name: "users-value", schema: avro(userV2)},
+			actions:    []string{actionRegister},
+		},
+		{
+			name:         "incompatible schema",
+			registered:   []string{userV1},
+			incompatible: true,
+			desired:      desiredSubject{name: "users-value", schema: avro(userV2)},
+			actions:      []string{actionIncompatible},
+		},
+		{
+			name:       "same level",
+			registered: []string{userV1},
+			level:      &backward,
+			desired:    desiredSubject{name: "users-value", schema: avro(userV1), level: &backward},
+		},
+		{
+			name:       "level changed",
+			registered: []string{userV1},
+			desired:    desiredSubject{name: "users-value", schema: avro(userV1), level: &full},
+			actions:    []string{actionCompatibility},
+		},
+		{
+			name:       "compatible under the planned level",
+			registered: []string{userV1},
+			// the registry would refuse it, but it's not asked under its current level.
+			incompatible: true,
+			desired:      desiredSubject{name: "users-value", schema: avro(userV2), level: &full},
+			actions:      []string{actionCompatibility, actionRegister},
+		},
+		{
+			name:       "incompatible under the planned level",
+			registered: []string{userV1},
+			desired:    desiredSubject{name: "users-value", schema: avro(userV3), level: &full},
+			actions:    []string{actionCompatibility, actionIncompatible},
+		},
+		{
+			name:       "checked under the planned level when applied",
+			registered: []string{`{"type":"object"}`},
+			desired: desiredSubject{name: "users-value", level: &full,
+				schema: schemaregistry.Schema{Schema: `{"type":"object","properties":{}}`, SchemaType: schemaregistry.JSON}},
+			actions: []string{actionCompatibility, actionRegister},
+			recheck: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newTestClient(t)
+			for _, schema := range tt.registered {
+				_, err := c.RegisterSchema("users-value", schemaregistry.Schema{Schema: schema, SchemaType: tt.desired.schema.SchemaType})
+				assert.NoError(t, err)
+			}
+			if tt.level != nil {
+				_, err := c.SetConfigLevel(*tt.level, "users-value")
+				assert.NoError(t, err)
+			}
+			if tt.incompatible {
+				srv.SetCompatibilityFunc(func(string, schemaregistry.Schema, []schemaregistry.Schema) []string {
+					return []string{"incompatible"}
+				})
+			}
+
+			changes, err := makePlan(c, []desiredSubject{tt.desired})
+			assert.NoError(t, err)
+			var actions []string
+			for _, change := range changes {
+				actions = append(actions, change.Action)
+			}
+			assert.Equal(t, tt.actions, actions)
+			if len(changes) > 0 {
+				assert.Equal(t, tt.recheck, changes[len(changes)-1].Recheck)
+			}
+		})
+	}
+}
+
+func TestMakePlan_PendingReferences(t *testing.T) {
+	_, c := newTestClient(t)
+	c.RegisterNewSchema("address-value", address)
+	c.RegisterSchema("orders-value", schemaregistry.Schema{
+		Schema:     order,
+		References: []schemaregistry.SchemaReference{{Name: "Address", Subject: "address-value", Version: 1}},
+	})
+
+	// the new address version is not registered yet, the order can't be checked against it.
+	refs := []schemaregistry.SchemaReference{{Name: "Address", Subject: "address-value", Version: 2}}
+	changes, err := makePlan(c, []desiredSubject{
+		{name: "address-value", schema: schemaregistry.Schema{Schema: userV1, SchemaType: schemaregistry.Avro}},
+		{name: "orders-value", schema: schemaregistry.Schema{Schema: order, SchemaType: schemaregistry.Avro, References: refs}},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, actionRegister, changes[0].Action)
+		assert.Equal(t, "orders-value", changes[1].Subject)
+		assert.Equal(t, actionRegister, changes[1].Action)
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	setMode    string
+	forceMode  bool
+	deleteMode bool
+)
+
+var modeCmd = &cobra.Command{
+	Use:   "mode [subject]",
+	Short: "retrieves or changes the global or subject specific mode",
+	Long: `The mode tells which writes the registry accepts, it may be "READWRITE", "READONLY" or "IMPORT".
+Without a subject the global mode is used. A subject without a mode of its own uses the global one.
+Use --set to change the mode, --force is needed to switch a registry with schemas to "IMPORT",
+and --delete to drop the mode of a subject.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("only one subject allowed")
+		}
+		subj := ""
+		if len(args) == 1 {
+			subj = args[0]
+		}
+
+		client := assertClient()
+		var (
+			mode schemaregistry.Mode
+			err  error
+		)
+		switch {
+		case deleteMode && setMode != "":
+			return fmt.Errorf("--set and --delete can't be used together")
+		case deleteMode:
+			mode, err = client.DeleteMode(subj)
+		case setMode != "":
+			set := client.SetMode
+			if forceMode {
+				set = client.SetModeForced
+			}
+			mode, err = set(schemaregistry.Mode(strings.ToUpper(setMode)), subj)
+		default:
+			mode, err = client.GetEffectiveMode(subj)
+		}
+		if err != nil {
+			return err
+		}
+
+		res := struct {
+			Subject string              `json:"subject,omitempty"`
+			Mode    schemaregistry.Mode `json:"mode"`
+		}{subj, mode}
+		if subj == "" {
+			subj = "global"
+		}
+		t := table{header: []string{"SUBJECT", "MODE"}}
+		t.add(subj, mode)
+		return printResult(res, t, func() {
+			fmt.Printf("%s mode: %s\n", subj, mode)
+		})
+	},
+}
+
+func init() {
+	modeCmd.Flags().StringVar(&setMode, "set", "", "mode to set: READWRITE, READONLY or IMPORT")
+	modeCmd.Flags().BoolVar(&forceMode, "force", false, "switch to IMPORT even when there are schemas")
+	modeCmd.Flags().BoolVar(&deleteMode, "delete", false, "delete the mode of the subject")
+	RootCmd.AddCommand(modeCmd)
+}
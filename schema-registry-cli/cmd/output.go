@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The formats of the "--output" flag.
+const (
+	outputPlain = "plain"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+// output is the value of the "--output" flag.
+var output string
+
+func checkOutput() error {
+	switch output {
+	case outputPlain, outputJSON, outputYAML, outputTable:
+		return nil
+	default:
+		return fmt.Errorf("unknown output %q, expected plain, json, yaml or table", output)
+	}
+}
+
+// table is the form of a result for "--output table".
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(row ...interface{}) {
+	cells := make([]string, len(row))
+	for i, cell := range row {
+		cells[i] = fmt.Sprint(cell)
+	}
+	t.rows = append(t.rows, cells)
+}
+
+func (t table) print() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// printResult prints the result of a command in the format of the "--output" flag: v as a JSON
+// or YAML document, t as a table, or with plain, the human format.
+func printResult(v interface{}, t table, plain func()) error {
+	switch output {
+	case outputJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case outputYAML:
+		// the document goes through JSON, so it has the field names of the json tags.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		data, err = yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(data)
+	case outputTable:
+		t.print()
+	default:
+		plain()
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// autoApprove is the value of the "--auto-approve" flag of the "apply" command.
+var autoApprove bool
+
+const manifestHelp = `The manifest directory holds YAML files which map the subjects to their schema files,
+type, references and compatibility level:
+
+  subjects:
+    users-value:
+      file: users.avsc
+      compatibility: FULL
+    orders-value:
+      file: orders.avsc
+      references:
+        - name: com.example.User
+          subject: users-value
+          version: 1
+
+The schema files are relative to the manifest file. The subjects which don't have their
+schema registered get a new version, the compatibility level is changed when it's set and
+differs. A schema which is not compatible with the latest version, under the planned level,
+blocks the plan.
+`
+
+var planCmd = &cobra.Command{
+	Use:   "plan <manifest-dir>",
+	Short: "shows the changes which would bring the registry in sync with a manifest",
+	Long: manifestHelp + `
+The command exits with code 2 when a schema is not compatible.
+`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected 1 argument")
+		}
+		changes, err := planManifest(args[0])
+		if err != nil {
+			return err
+		}
+		if err := printPlan(changes); err != nil {
+			return err
+		}
+		if countActions(changes)[actionIncompatible] > 0 {
+			os.Exit(exitIncompatible)
+		}
+		return nil
+	},
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <manifest-dir>",
+	Short: "brings the registry in sync with a manifest",
+	Long: manifestHelp + `
+The plan is shown and applied once confirmed, or right away with --auto-approve.
+Nothing is applied when a schema is not compatible.
+`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected 1 argument")
+		}
+		changes, err := planManifest(args[0])
+		if err != nil {
+			return err
+		}
+		if err := printPlan(changes); err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		if countActions(changes)[actionIncompatible] > 0 {
+			return fmt.Errorf("the plan has incompatible schemas, nothing was applied")
+		}
+		if !autoApprove && !confirm("apply the plan?") {
+			return nil
+		}
+		return applyPlan(changes)
+	},
+}
+
+func planManifest(dir string) ([]change, error) {
+	subjects, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	return makePlan(assertClient(), subjects)
+}
+
+func applyPlan(changes []change) error {
+	client := assertClient()
+	for _, c := range changes {
+		switch c.Action {
+		case actionCompatibility:
+			if _, err := client.SetConfigLevel(c.level, c.Subject); err != nil {
+				return fmt.Errorf("%s: %v", c.Subject, err)
+			}
+			fmt.Fprintf(os.Stderr, "%s: compatibility set to %s\n", c.Subject, c.To)
+		case actionCreate, actionRegister:
+			if c.Recheck {
+				res, err := client.CheckLatestCompatibilityVerbose(c.Subject, c.schema)
+				if err != nil {
+					return fmt.Errorf("%s: %v", c.Subject, err)
+				}
+				if !res.IsCompatible {
+					return fmt.Errorf("%s: %s is not compatible with the latest version: %s", c.Subject, c.File, strings.Join(res.Messages, "; "))
+				}
+			}
+			id, err := client.RegisterSchema(c.Subject, c.schema)
+			if err != nil {
+				return fmt.Errorf("%s: %v", c.Subject, err)
+			}
+			fmt.Fprintf(os.Stderr, "%s: registered with id %d\n", c.Subject, id)
+		}
+	}
+	fmt.Fprintln(os.Stderr, "apply complete")
+	return nil
+}
+
+func countActions(changes []change) map[string]int {
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Action]++
+	}
+	return counts
+}
+
+func printPlan(changes []change) error {
+	t := table{header: []string{"SUBJECT", "ACTION", "FILE", "FROM", "TO", "MESSAGES"}}
+	for _, c := range changes {
+		t.add(c.Subject, c.Action, c.File, c.From, c.To, strings.Join(c.Messages, "; "))
+	}
+	return printResult(changes, t, func() {
+		if len(changes) == 0 {
+			fmt.Println("no changes, the registry is in sync with the manifest")
+			return
+		}
+		for _, c := range changes {
+			switch c.Action {
+			case actionCreate:
+				color.Green("  + %s: create the subject with %s", c.Subject, c.File)
+			case actionRegister:
+				color.Yellow("  ~ %s: register a new version from %s", c.Subject, c.File)
+				for _, msg := range c.Messages {
+					color.Yellow("      - %s", msg)
+				}
+			case actionCompatibility:
+				color.Yellow("  ~ %s: compatibility %s -> %s", c.Subject, c.From, c.To)
+			case actionIncompatible:
+				color.Red("  ! %s: %s is not compatible with the latest version", c.Subject, c.File)
+				for _, msg := range c.Messages {
+					color.Red("      - %s", msg)
+				}
+			}
+		}
+
+		counts := countActions(changes)
+		fmt.Printf("\nPlan: %d to create, %d to register, %d compatibility changes, %d incompatible.\n",
+			counts[actionCreate], counts[actionRegister], counts[actionCompatibility], counts[actionIncompatible])
+	})
+}
+
+func init() {
+	applyCmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "apply without asking for confirmation")
+	RootCmd.AddCommand(planCmd)
+	RootCmd.AddCommand(applyCmd)
+}
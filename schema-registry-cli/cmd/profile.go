@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultConfigFile is the name of the config file in the home directory.
+const defaultConfigFile = ".schema-registry-cli.yaml"
+
+// profile holds the connection settings of a registry, the keys are the ones of the flags,
+// which override them, e.g.:
+//
+//	current: dev
+//	profiles:
+//	  dev:
+//	    url: http://localhost:8081
+//	  prod:
+//	    url: https://registry.example.com
+//	    basic_auth_user: key
+//	    basic_auth_password: secret
+//	    ca_cert: /etc/ssl/registry-ca.pem
+type profile struct {
+	URL               string `yaml:"url,omitempty"`
+	BasicAuthUser     string `yaml:"basic_auth_user,omitempty"`
+	BasicAuthPassword string `yaml:"basic_auth_password,omitempty"`
+	BearerToken       string `yaml:"bearer_token,omitempty"`
+	CACert            string `yaml:"ca_cert,omitempty"`
+	ClientCert        string `yaml:"client_cert,omitempty"`
+	ClientKey         string `yaml:"client_key,omitempty"`
+}
+
+// settings returns the non empty settings of the profile by viper key.
+func (p profile) settings() map[string]interface{} {
+	all := map[string]string{
+		"url":                 p.URL,
+		"basic_auth_user":     p.BasicAuthUser,
+		"basic_auth_password": p.BasicAuthPassword,
+		"bearer_token":        p.BearerToken,
+		"ca_cert":             p.CACert,
+		"client_cert":         p.ClientCert,
+		"client_key":          p.ClientKey,
+	}
+	settings := make(map[string]interface{})
+	for key, value := range all {
+		if value != "" {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// clientOptions returns the client options of the authentication and TLS settings of the profile.
+func (p profile) clientOptions() []schemaregistry.Option {
+	var opts []schemaregistry.Option
+	if p.BasicAuthUser != "" {
+		opts = append(opts, schemaregistry.WithBasicAuth(p.BasicAuthUser, p.BasicAuthPassword))
+	}
+	if p.BearerToken != "" {
+		opts = append(opts, schemaregistry.WithBearerToken(schemaregistry.StaticTokenSource(p.BearerToken)))
+	}
+	if p.CACert != "" {
+		opts = append(opts, schemaregistry.WithCACertFile(p.CACert))
+	}
+	if p.ClientCert != "" {
+		opts = append(opts, schemaregistry.WithClientCertFile(p.ClientCert, p.ClientKey))
+	}
+	return opts
+}
+
+// cliConfig is the content of the config file.
+type cliConfig struct {
+	// Current is the profile used when the "--profile" flag is not set.
+	Current  string             `yaml:"current,omitempty"`
+	Profiles map[string]profile `yaml:"profiles,omitempty"`
+}
+
+func configFile() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, defaultConfigFile), nil
+}
+
+// readConfig reads the config file, a missing file is an empty config.
+func readConfig() (cliConfig, string, error) {
+	var cfg cliConfig
+	file, err := configFile()
+	if err != nil {
+		return cfg, "", err
+	}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return cfg, file, nil
+	}
+	if err != nil {
+		return cfg, file, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, file, fmt.Errorf("%s: %v", file, err)
+	}
+	return cfg, file, nil
+}
+
+func writeConfig(cfg cliConfig, file string) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	// the profiles may hold credentials.
+	return ioutil.WriteFile(file, data, 0600)
+}
+
+// loadProfile sets the settings of the selected profile, or of the current one, as the config of viper,
+// below the flags and the environment variables.
+func loadProfile() error {
+	cfg, file, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	name := viper.GetString("profile")
+	if name == "" {
+		name = cfg.Current
+	}
+	if name == "" {
+		return nil
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found in %s", name, file)
+	}
+	log.Printf("profile: %s\n", name)
+	return viper.MergeConfigMap(p.settings())
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "manages the connection profiles of the config file",
+	Long: `A profile holds the url, the authentication and the TLS settings of a registry,
+the flags and the environment variables override them. Profiles are defined
+in ~/` + defaultConfigFile + `, or in the file of the --config flag.
+`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "lists the profiles, the current one is marked with a *",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			return fmt.Errorf("expected no arguments")
+		}
+		cfg, _, err := readConfig()
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(cfg.Profiles))
+		for name := range cfg.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		type profileResult struct {
+			Name    string `json:"name"`
+			URL     string `json:"url,omitempty"`
+			Current bool   `json:"current"`
+		}
+		res := make([]profileResult, 0, len(names))
+		t := table{header: []string{"NAME", "URL", "CURRENT"}}
+		for _, name := range names {
+			res = append(res, profileResult{name, cfg.Profiles[name].URL, name == cfg.Current})
+			t.add(name, cfg.Profiles[name].URL, name == cfg.Current)
+		}
+		return printResult(res, t, func() {
+			for _, p := range res {
+				mark := " "
+				if p.Current {
+					mark = "*"
+				}
+				fmt.Printf("%s %s\t%s\n", mark, p.Name, p.URL)
+			}
+		})
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <profile>",
+	Short: "sets the current profile",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected 1 argument")
+		}
+		cfg, file, err := readConfig()
+		if err != nil {
+			return err
+		}
+		if _, ok := cfg.Profiles[args[0]]; !ok {
+			return fmt.Errorf("profile %q not found in %s", args[0], file)
+		}
+
+		cfg.Current = args[0]
+		if err := writeConfig(cfg, file); err != nil {
+			return err
+		}
+		res := struct {
+			Current string `json:"current"`
+		}{args[0]}
+		t := table{header: []string{"CURRENT"}}
+		t.add(args[0])
+		return printResult(res, t, func() {
+			fmt.Printf("using profile %s\n", args[0])
+		})
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	RootCmd.AddCommand(profileCmd)
+}
@@ -14,10 +14,17 @@ import (
 )
 
 var (
-	cfgFile     string
-	registryURL string
-	verbose     bool
-	nocolor     bool
+	cfgFile           string
+	profileName       string
+	registryURL       string
+	basicAuthUser     string
+	basicAuthPassword string
+	bearerToken       string
+	caCert            string
+	clientCert        string
+	clientKey         string
+	verbose           bool
+	nocolor           bool
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -25,14 +32,24 @@ var RootCmd = &cobra.Command{
 	Use:   "schema-registry-cli",
 	Short: "A command line interface for the Confluent schema registry",
 	Long:  `A command line interface for the Confluent schema registry`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		if !verbose {
 			log.SetOutput(ioutil.Discard)
 		}
 		if nocolor {
 			color.NoColor = true
 		}
+		if err := checkOutput(); err != nil {
+			return err
+		}
+		// the profile commands must work when the current profile is broken, to fix it.
+		if cmd != profileCmd && cmd.Parent() != profileCmd {
+			if err := loadProfile(); err != nil {
+				return err
+			}
+		}
 		log.Printf("schema registry url: %s\n", viper.Get("url"))
+		return nil
 	},
 }
 
@@ -48,8 +65,31 @@ func Execute() {
 func init() {
 	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "be verbose")
 	RootCmd.PersistentFlags().BoolVarP(&nocolor, "no-color", "n", false, "dont color output")
+	RootCmd.PersistentFlags().StringVarP(&output, "output", "o", outputPlain, "output format: plain, json, yaml or table")
+	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file of the profiles (default ~/"+defaultConfigFile+")")
+	RootCmd.PersistentFlags().StringVarP(&profileName, "profile", "p", "", "profile of the config file to use, overrides SCHEMA_REGISTRY_PROFILE and the current profile")
 	RootCmd.PersistentFlags().StringVarP(&registryURL, "url", "e", schemaregistry.DefaultURL, "schema registry url, overrides SCHEMA_REGISTRY_URL")
+	RootCmd.PersistentFlags().StringVar(&basicAuthUser, "basic-auth-user", "", "basic auth username or API key, overrides SCHEMA_REGISTRY_BASIC_AUTH_USER")
+	RootCmd.PersistentFlags().StringVar(&basicAuthPassword, "basic-auth-password", "", "basic auth password or API secret, overrides SCHEMA_REGISTRY_BASIC_AUTH_PASSWORD")
+	RootCmd.PersistentFlags().StringVar(&bearerToken, "bearer-token", "", "bearer token, overrides SCHEMA_REGISTRY_BEARER_TOKEN")
+	RootCmd.PersistentFlags().StringVar(&caCert, "ca-cert", "", "PEM file of the CA bundle to trust, overrides SCHEMA_REGISTRY_CA_CERT")
+	RootCmd.PersistentFlags().StringVar(&clientCert, "client-cert", "", "PEM file of the client certificate, overrides SCHEMA_REGISTRY_CLIENT_CERT")
+	RootCmd.PersistentFlags().StringVar(&clientKey, "client-key", "", "PEM file of the client private key, overrides SCHEMA_REGISTRY_CLIENT_KEY")
 	viper.SetEnvPrefix("schema_registry")
+	viper.BindPFlag("profile", RootCmd.PersistentFlags().Lookup("profile"))
+	viper.BindEnv("profile")
 	viper.BindPFlag("url", RootCmd.PersistentFlags().Lookup("url"))
 	viper.BindEnv("url")
+	viper.BindPFlag("basic_auth_user", RootCmd.PersistentFlags().Lookup("basic-auth-user"))
+	viper.BindEnv("basic_auth_user")
+	viper.BindPFlag("basic_auth_password", RootCmd.PersistentFlags().Lookup("basic-auth-password"))
+	viper.BindEnv("basic_auth_password")
+	viper.BindPFlag("bearer_token", RootCmd.PersistentFlags().Lookup("bearer-token"))
+	viper.BindEnv("bearer_token")
+	viper.BindPFlag("ca_cert", RootCmd.PersistentFlags().Lookup("ca-cert"))
+	viper.BindEnv("ca_cert")
+	viper.BindPFlag("client_cert", RootCmd.PersistentFlags().Lookup("client-cert"))
+	viper.BindEnv("client_cert")
+	viper.BindPFlag("client_key", RootCmd.PersistentFlags().Lookup("client-key"))
+	viper.BindEnv("client_key")
 }
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/spf13/cobra"
+)
+
+var deleteConfig bool
+
+var setConfigCmd = &cobra.Command{
+	Use:   "set-config [subject] <compatibility-level>",
+	Short: "changes global or subject specific configuration",
+	Long: `Sets the compatibility level of a specific subject, or the global one when no subject is given.
+Compatibility levels may be: "NONE", "BACKWARD", "BACKWARD_TRANSITIVE", "FORWARD", "FORWARD_TRANSITIVE",
+"FULL" and "FULL_TRANSITIVE". With --delete the level of the subject is deleted, the subject then uses
+the global level, and the global level is reset to the registry default.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := assertClient()
+
+		if deleteConfig {
+			if len(args) > 1 {
+				return fmt.Errorf("only one subject allowed")
+			}
+			subj := ""
+			if len(args) == 1 {
+				subj = args[0]
+			}
+			cfg, err := client.DeleteConfig(subj)
+			if err != nil {
+				return err
+			}
+			return printConfig(cfg, subj)
+		}
+
+		var subj, level string
+		switch len(args) {
+		case 1:
+			level = args[0]
+		case 2:
+			subj, level = args[0], args[1]
+		default:
+			return fmt.Errorf("expected 1 to 2 arguments")
+		}
+
+		cl, err := schemaregistry.ParseCompatibilityLevel(level)
+		if err != nil {
+			return err
+		}
+		cfg, err := client.SetConfigLevel(cl, subj)
+		if err != nil {
+			return err
+		}
+		return printConfig(cfg, subj)
+	},
+}
+
+func init() {
+	setConfigCmd.Flags().BoolVar(&deleteConfig, "delete", false, "delete the compatibility level instead")
+	RootCmd.AddCommand(setConfigCmd)
+}
@@ -12,18 +12,29 @@ var subjectsCmd = &cobra.Command{
 	Short: "lists all registered subjects",
 	Long:  ``,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		subs, err := assertClient().Subjects()
+		client := assertClient()
+		subjects := client.Subjects
+		if includeDeleted {
+			subjects = client.SubjectsIncludingDeleted
+		}
+		subs, err := subjects()
 		if err != nil {
 			return err
 		}
 		log.Printf("there are %d subjects\n", len(subs))
+		t := table{header: []string{"SUBJECT"}}
 		for _, s := range subs {
-			fmt.Println(s)
+			t.add(s)
 		}
-		return nil
+		return printResult(subs, t, func() {
+			for _, s := range subs {
+				fmt.Println(s)
+			}
+		})
 	},
 }
 
 func init() {
+	addDeletedFlag(subjectsCmd)
 	RootCmd.AddCommand(subjectsCmd)
 }
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/spf13/cobra"
+)
+
+// the values of the flags of the "sync" command.
+var (
+	targetURL               string
+	targetProfile           string
+	targetBasicAuthUser     string
+	targetBasicAuthPassword string
+	targetBearerToken       string
+	syncSubjects            []string
+	syncRegexps             []string
+	syncExcludes            []string
+	syncRenames             []string
+	syncNewIDs              bool
+	syncWatch               bool
+	syncInterval            time.Duration
+)
+
+var syncCmd = &cobra.Command{
+	Use:     "sync",
+	Aliases: []string{"migrate"},
+	Short:   "copies the subjects of the registry to a target registry",
+	Long: `The versions of the selected subjects which the target registry doesn't have are
+copied in the order of their IDs, the referenced schemas first. The schemas keep
+their IDs and versions: the target subjects are switched to IMPORT mode while they
+are copied, when the target refuses it, or when an ID is taken in the target, they
+get new IDs. With --new-ids they always get new IDs.
+The target is verified once the subjects are copied.
+
+The subjects are selected with --subject globs and --regex expressions, all of them
+when none is set, and dropped with --exclude globs. A --rename rule is an expression
+and its replacement, e.g. '^dev\.=prod.', the rules are applied in order to the names
+of the subjects and of the referenced subjects.
+
+With --watch the registry is polled every --interval, the new versions are copied
+as they come, until the command is interrupted.
+`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			return fmt.Errorf("expected no arguments")
+		}
+		target, err := targetClient()
+		if err != nil {
+			return err
+		}
+		filter, err := subjectFilter()
+		if err != nil {
+			return err
+		}
+		rename, err := subjectRename()
+		if err != nil {
+			return err
+		}
+		syncer := schemaregistry.NewSyncer(assertClient(), target,
+			schemaregistry.WithSubjectFilter(filter),
+			schemaregistry.WithSubjectRename(rename),
+			schemaregistry.WithPreservedIDs(!syncNewIDs),
+		)
+
+		if syncWatch {
+			return watch(syncer)
+		}
+
+		copied, err := syncer.Sync()
+		if err != nil {
+			return err
+		}
+		if err := printSynced(copied); err != nil {
+			return err
+		}
+		problems, err := syncer.Verify()
+		if err != nil {
+			return err
+		}
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, p)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("the target is not in sync, %d problems found", len(problems))
+		}
+		fmt.Fprintln(os.Stderr, "the target is in sync")
+		return nil
+	},
+}
+
+// targetClient returns the client of the target registry, the target flags override the target profile.
+func targetClient() (*schemaregistry.Client, error) {
+	var p profile
+	if targetProfile != "" {
+		cfg, file, err := readConfig()
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if p, ok = cfg.Profiles[targetProfile]; !ok {
+			return nil, fmt.Errorf("profile %q not found in %s", targetProfile, file)
+		}
+	}
+	if targetURL != "" {
+		p.URL = targetURL
+	}
+	if targetBasicAuthUser != "" {
+		p.BasicAuthUser, p.BasicAuthPassword = targetBasicAuthUser, targetBasicAuthPassword
+	}
+	if targetBearerToken != "" {
+		p.BearerToken = targetBearerToken
+	}
+	if p.URL == "" {
+		return nil, fmt.Errorf("the target registry is required, set --target-url or --target-profile")
+	}
+	return schemaregistry.NewClientFromURL(p.URL, p.clientOptions()...)
+}
+
+// subjectFilter returns the filter of the --subject, --regex and --exclude flags.
+func subjectFilter() (func(subject string) bool, error) {
+	for _, pattern := range append(syncSubjects, syncExcludes...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %v", pattern, err)
+		}
+	}
+	var regexps []*regexp.Regexp
+	for _, expr := range syncRegexps {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %v", expr, err)
+		}
+		regexps = append(regexps, re)
+	}
+
+	return func(subject string) bool {
+		for _, pattern := range syncExcludes {
+			if ok, _ := path.Match(pattern, subject); ok {
+				return false
+			}
+		}
+		if len(syncSubjects) == 0 && len(regexps) == 0 {
+			return true
+		}
+		for _, pattern := range syncSubjects {
+			if ok, _ := path.Match(pattern, subject); ok {
+				return true
+			}
+		}
+		for _, re := range regexps {
+			if re.MatchString(subject) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// subjectRename returns the rename function of the --rename rules.
+func subjectRename() (func(subject string) string, error) {
+	type rule struct {
+		re          *regexp.Regexp
+		replacement string
+	}
+	var rules []rule
+	for _, r := range syncRenames {
+		i := strings.Index(r, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid rename rule %q, expected expression=replacement", r)
+		}
+		re, err := regexp.Compile(r[:i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rename rule %q: %v", r, err)
+		}
+		rules = append(rules, rule{re, r[i+1:]})
+	}
+
+	return func(subject string) string {
+		for _, r := range rules {
+			subject = r.re.ReplaceAllString(subject, r.replacement)
+		}
+		return subject
+	}, nil
+}
+
+// watch syncs every interval until the command is interrupted, a failed sync is retried on the next one.
+func watch(syncer *schemaregistry.Syncer) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	fmt.Fprintf(os.Stderr, "watching the registry every %s, press Ctrl+C to stop\n", syncInterval)
+	err := syncer.Watch(ctx, syncInterval, func(copied []schemaregistry.SyncedSchema, err error) {
+		// an interrupted sync is not a failure, the modes of the target subjects are restored anyway.
+		if err != nil && err != context.Canceled {
+			fmt.Fprintf(os.Stderr, "sync failed: %v\n", err)
+		}
+		if len(copied) > 0 {
+			if err := printSynced(copied); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	})
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+func printSynced(copied []schemaregistry.SyncedSchema) error {
+	if copied == nil {
+		// an empty list, not null, in the json and yaml documents.
+		copied = []schemaregistry.SyncedSchema{}
+	}
+	t := table{header: []string{"SUBJECT", "VERSION", "ID", "TARGET SUBJECT", "TARGET ID"}}
+	for _, s := range copied {
+		t.add(s.Subject, s.Version, s.ID, s.TargetSubject, s.TargetID)
+	}
+	return printResult(copied, t, func() {
+		if len(copied) == 0 {
+			fmt.Println("no new versions")
+			return
+		}
+		for _, s := range copied {
+			fmt.Printf("%s version %d (id %d) -> %s (id %d)\n", s.Subject, s.Version, s.ID, s.TargetSubject, s.TargetID)
+		}
+	})
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&targetURL, "target-url", "", "url of the target registry, overrides the one of --target-profile")
+	syncCmd.Flags().StringVar(&targetProfile, "target-profile", "", "profile of the config file of the target registry")
+	syncCmd.Flags().StringVar(&targetBasicAuthUser, "target-basic-auth-user", "", "basic auth username or API key of the target registry")
+	syncCmd.Flags().StringVar(&targetBasicAuthPassword, "target-basic-auth-password", "", "basic auth password or API secret of the target registry")
+	syncCmd.Flags().StringVar(&targetBearerToken, "target-bearer-token", "", "bearer token of the target registry")
+	syncCmd.Flags().StringArrayVarP(&syncSubjects, "subject", "s", nil, "glob of the subjects to copy, can be repeated")
+	syncCmd.Flags().StringArrayVar(&syncRegexps, "regex", nil, "regular expression of the subjects to copy, can be repeated")
+	syncCmd.Flags().StringArrayVar(&syncExcludes, "exclude", nil, "glob of the subjects not to copy, can be repeated")
+	syncCmd.Flags().StringArrayVar(&syncRenames, "rename", nil, "rename rule as expression=replacement, can be repeated")
+	syncCmd.Flags().BoolVar(&syncNewIDs, "new-ids", false, "register the schemas with the IDs the target gives them, don't use IMPORT mode")
+	syncCmd.Flags().BoolVarP(&syncWatch, "watch", "w", false, "keep polling the registry and copy the new versions")
+	syncCmd.Flags().DurationVar(&syncInterval, "interval", 30*time.Second, "polling interval of --watch")
+	RootCmd.AddCommand(syncCmd)
+}
@@ -15,15 +15,29 @@ var versionsCmd = &cobra.Command{
 			return fmt.Errorf("expected 1 argument")
 		}
 		client := assertClient()
-		vers, err := client.Versions(args[0])
+		versions := client.Versions
+		if includeDeleted {
+			versions = client.VersionsIncludingDeleted
+		}
+		vers, err := versions(args[0])
 		if err != nil {
 			return err
 		}
-		fmt.Printf("%v\n", vers)
-		return nil
+		res := struct {
+			Subject  string `json:"subject"`
+			Versions []int  `json:"versions"`
+		}{args[0], vers}
+		t := table{header: []string{"SUBJECT", "VERSION"}}
+		for _, v := range vers {
+			t.add(args[0], v)
+		}
+		return printResult(res, t, func() {
+			fmt.Printf("%v\n", vers)
+		})
 	},
 }
 
 func init() {
+	addDeletedFlag(versionsCmd)
 	RootCmd.AddCommand(versionsCmd)
 }
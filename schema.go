@@ -1,20 +1,85 @@
 package schemaregistry
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/bjornm82/schema-registry/avro"
 )
 
+// SchemaType is the format of a schema, the registry treats a schema without a type as Avro.
+type SchemaType string
+
+// The schema types supported by the registry.
+const (
+	Avro     SchemaType = "AVRO"
+	JSON     SchemaType = "JSON"
+	Protobuf SchemaType = "PROTOBUF"
+)
+
+// SchemaReference is a reference from a schema to another registered schema,
+// e.g. an Avro named type defined in another subject or an imported Protobuf file.
+type SchemaReference struct {
+	// Name is the name the schema refers to the referenced one with,
+	// the fully qualified name for Avro, the import path for Protobuf and the URL for JSON Schema.
+	Name string `json:"name"`
+	// Subject of the referenced schema.
+	Subject string `json:"subject"`
+	// Version of the referenced schema.
+	Version int `json:"version"`
+}
+
 // Schema describes a schema, look `GetSchema` for more.
 type Schema struct {
-	// Schema is the Avro schema string.
+	// Schema is the schema string.
 	Schema string `json:"schema"`
 	// Subject where the schema is registered for.
 	Subject string `json:"subject"`
 	// Version of the returned schema.
 	Version int `json:"version"`
 	ID      int `json:"id,omitempty"`
+	// SchemaType is the format of the schema, empty means `Avro`.
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+	// References are the schemas this schema refers to.
+	References []SchemaReference `json:"references,omitempty"`
+}
+
+// schemaRequest returns the request body of the schema for registrations, lookups and compatibility checks.
+// The type is omitted for Avro schemas, so registries which don't support other types accept them.
+func (s Schema) schemaRequest() schemaRequestJSON {
+	req := schemaRequestJSON{Schema: s.Schema, References: s.References}
+	if s.SchemaType != Avro {
+		req.SchemaType = s.SchemaType
+	}
+	return req
+}
+
+// withDefaultType sets the type of a schema returned by the registry, which omits the `Avro` one.
+func (s Schema) withDefaultType() Schema {
+	if s.SchemaType == "" {
+		s.SchemaType = Avro
+	}
+	return s
+}
+
+// WithSchemaValidation parses the Avro schemas before they are registered and rejects the malformed ones
+// without a request, the registry reports them with an `invalid schema` error otherwise.
+func WithSchemaValidation() Option {
+	return func(c *Client) {
+		c.validate = true
+	}
+}
+
+// validate parses an Avro schema to reject a malformed one before it's sent to the registry. The schemas
+// of the other types and the ones with references, which would need to be fetched first, are not checked.
+func (s Schema) validate() error {
+	if s.withDefaultType().SchemaType != Avro || len(s.References) > 0 {
+		return nil
+	}
+	_, err := avro.Parse(s.Schema)
+	return err
 }
 
 // RegisterNewSchema registers a schema.
@@ -22,18 +87,67 @@ type Schema struct {
 // this schema from the schemas resource and is different from
 // the schema’s version which is associated with that name.
 func (c *Client) RegisterNewSchema(subject string, avroSchema string) (int, error) {
+	return c.RegisterNewSchemaContext(context.Background(), subject, avroSchema)
+}
+
+// RegisterNewSchemaContext same as `RegisterNewSchema` but it accepts a context to control the request's lifetime.
+func (c *Client) RegisterNewSchemaContext(ctx context.Context, subject string, avroSchema string) (int, error) {
+	if avroSchema == "" {
+		return 0, errRequired("avroSchema")
+	}
+
+	return c.RegisterSchemaContext(ctx, subject, Schema{Schema: avroSchema})
+}
+
+// RegisterSchema registers a schema of any type, e.g. `Schema{Schema: protoSchema, SchemaType: Protobuf}`.
+// The `Subject`, `Version` and `ID` fields of the schema are ignored.
+func (c *Client) RegisterSchema(subject string, schema Schema) (int, error) {
+	return c.RegisterSchemaContext(context.Background(), subject, schema)
+}
+
+// RegisterSchemaContext same as `RegisterSchema` but it accepts a context to control the request's lifetime.
+func (c *Client) RegisterSchemaContext(ctx context.Context, subject string, schema Schema) (int, error) {
 	if subject == "" {
 		return 0, errRequired("subject")
 	}
-	if avroSchema == "" {
-		return 0, errRequired("avroSchema")
+	if schema.Schema == "" {
+		return 0, errRequired("schema")
 	}
+	if c.validate {
+		if err := schema.validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	return c.register(ctx, subject, schema.schemaRequest())
+}
+
+// ImportSchema registers a schema under the subject with the ID and, when not zero, the version of "schema".
+// The registry accepts it only when the subject, or the whole registry, is in `Import` mode, look `SetMode`.
+func (c *Client) ImportSchema(subject string, schema Schema) (int, error) {
+	return c.ImportSchemaContext(context.Background(), subject, schema)
+}
 
-	schema := schemaOnlyJSON{
-		Schema: avroSchema,
+// ImportSchemaContext same as `ImportSchema` but it accepts a context to control the request's lifetime.
+func (c *Client) ImportSchemaContext(ctx context.Context, subject string, schema Schema) (int, error) {
+	if subject == "" {
+		return 0, errRequired("subject")
+	}
+	if schema.Schema == "" {
+		return 0, errRequired("schema")
+	}
+	if schema.ID <= 0 {
+		return 0, errRequired("schema ID")
 	}
 
-	send, err := json.Marshal(schema)
+	req := schema.schemaRequest()
+	req.ID = schema.ID
+	req.Version = schema.Version
+	return c.register(ctx, subject, req)
+}
+
+func (c *Client) register(ctx context.Context, subject string, req schemaRequestJSON) (int, error) {
+	send, err := json.Marshal(req)
 	if err != nil {
 		return 0, err
 	}
@@ -42,7 +156,7 @@ func (c *Client) RegisterNewSchema(subject string, avroSchema string) (int, erro
 	// POST /subjects/(string: subject)/versions
 
 	path := fmt.Sprintf(subjectPath+"/versions", subject)
-	resp, err := c.do(http.MethodPost, path, contentTypeSchemaJSON, send)
+	resp, err := c.do(ctx, http.MethodPost, path, contentTypeSchemaJSON, send)
 	if err != nil {
 		return 0, err
 	}
@@ -65,10 +179,15 @@ func JSONAvroSchema(avroSchema string) (json.RawMessage, error) {
 // GetSchemaByID returns the Auro schema string identified by the id.
 // id (int) – the globally unique identifier of the schema.
 func (c *Client) GetSchemaByID(subjectID int) (string, error) {
+	return c.GetSchemaByIDContext(context.Background(), subjectID)
+}
+
+// GetSchemaByIDContext same as `GetSchemaByID` but it accepts a context to control the request's lifetime.
+func (c *Client) GetSchemaByIDContext(ctx context.Context, subjectID int) (string, error) {
 	// # Get the schema for a particular subject id
 	// GET /schemas/ids/{int: id}
 	path := fmt.Sprintf(schemaPath, subjectID)
-	resp, err := c.do(http.MethodGet, path, "", nil)
+	resp, err := c.do(ctx, http.MethodGet, path, "", nil)
 	if err != nil {
 		return "", err
 	}
@@ -81,6 +200,49 @@ func (c *Client) GetSchemaByID(subjectID int) (string, error) {
 	return res.Schema, nil
 }
 
+// GetSchemaDetailsByID same as `GetSchemaByID` but it returns the schema along with its type.
+// The subject and version of the returned schema are empty, a schema may be registered under many of them.
+func (c *Client) GetSchemaDetailsByID(subjectID int) (Schema, error) {
+	return c.GetSchemaDetailsByIDContext(context.Background(), subjectID)
+}
+
+// GetSchemaDetailsByIDContext same as `GetSchemaDetailsByID` but it accepts a context to control the request's lifetime.
+func (c *Client) GetSchemaDetailsByIDContext(ctx context.Context, subjectID int) (Schema, error) {
+	// # Get the schema for a particular subject id
+	// GET /schemas/ids/{int: id}
+	path := fmt.Sprintf(schemaPath, subjectID)
+	resp, err := c.do(ctx, http.MethodGet, path, "", nil)
+	if err != nil {
+		return Schema{}, err
+	}
+
+	var res Schema
+	if err = c.readJSON(resp, &res); err != nil {
+		return Schema{}, err
+	}
+
+	res.ID = subjectID
+	return res.withDefaultType(), nil
+}
+
+// SchemaTypes returns the schema types supported by the registry.
+func (c *Client) SchemaTypes() ([]SchemaType, error) {
+	return c.SchemaTypesContext(context.Background())
+}
+
+// SchemaTypesContext same as `SchemaTypes` but it accepts a context to control the request's lifetime.
+func (c *Client) SchemaTypesContext(ctx context.Context) (types []SchemaType, err error) {
+	// # Get the schema types that are registered with Schema Registry
+	// GET /schemas/types
+	resp, err := c.do(ctx, http.MethodGet, schemaTypesPath, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.readJSON(resp, &types)
+	return
+}
+
 // SchemaLatestVersion is the only one valid string for the "versionID", it's the "latest" version string and it's used on `GetLatestSchema`.
 const SchemaLatestVersion = "latest"
 
@@ -115,7 +277,7 @@ func checkSchemaVersionID(versionID interface{}) error {
 // the version as integer and it will retrieve by a specific version.
 //
 // See `GetLatestSchema` and `GetSchemaAtVersion` instead.
-func (c *Client) getSubjectSchemaAtVersion(subject string, versionID interface{}) (s Schema, err error) {
+func (c *Client) getSubjectSchemaAtVersion(ctx context.Context, subject string, versionID interface{}) (s Schema, err error) {
 	if subject == "" {
 		err = errRequired("subject")
 		return
@@ -128,25 +290,39 @@ func (c *Client) getSubjectSchemaAtVersion(subject string, versionID interface{}
 	// # Get the schema at a particular version
 	// GET /subjects/(string: subject)/versions/(versionId: "latest" | int)
 	path := fmt.Sprintf(subjectPath+"/versions/%v", subject, versionID)
-	resp, respErr := c.do(http.MethodGet, path, "", nil)
+	resp, respErr := c.do(ctx, http.MethodGet, path, "", nil)
 	if respErr != nil {
 		err = respErr
 		return
 	}
 
-	err = c.readJSON(resp, &s)
+	if err = c.readJSON(resp, &s); err != nil {
+		return
+	}
+
+	s = s.withDefaultType()
 	return
 }
 
 // GetSchemaBySubject returns the schema for a particular subject and version.
 func (c *Client) GetSchemaBySubject(subject string, versionID int) (Schema, error) {
-	return c.getSubjectSchemaAtVersion(subject, versionID)
+	return c.GetSchemaBySubjectContext(context.Background(), subject, versionID)
+}
+
+// GetSchemaBySubjectContext same as `GetSchemaBySubject` but it accepts a context to control the request's lifetime.
+func (c *Client) GetSchemaBySubjectContext(ctx context.Context, subject string, versionID int) (Schema, error) {
+	return c.getSubjectSchemaAtVersion(ctx, subject, versionID)
 }
 
 // GetLatestSchema returns the latest version of a schema.
 // See `GetSchemaAtVersion` to retrieve a subject schema by a specific version.
 func (c *Client) GetLatestSchema(subject string) (Schema, error) {
-	return c.getSubjectSchemaAtVersion(subject, SchemaLatestVersion)
+	return c.GetLatestSchemaContext(context.Background(), subject)
+}
+
+// GetLatestSchemaContext same as `GetLatestSchema` but it accepts a context to control the request's lifetime.
+func (c *Client) GetLatestSchemaContext(ctx context.Context, subject string) (Schema, error) {
+	return c.getSubjectSchemaAtVersion(ctx, subject, SchemaLatestVersion)
 }
 
 // subject (string) – Name of the subject
@@ -160,55 +336,79 @@ func (c *Client) GetLatestSchema(subject string) (Schema, error) {
 // the version as integer and it will retrieve by a specific version.
 //
 // See `IsSchemaCompatible` and `IsLatestSchemaCompatible` instead.
-func (c *Client) isSchemaCompatibleAtVersion(subject string, avroSchema string, versionID interface{}) (combatible bool, err error) {
-	if subject == "" {
-		err = errRequired("subject")
-		return
-	}
-	if avroSchema == "" {
-		err = errRequired("avroSchema")
+func (c *Client) isSchemaCompatibleAtVersion(ctx context.Context, subject string, schema Schema, versionID interface{}) (combatible bool, err error) {
+	if err = checkSchemaVersionID(versionID); err != nil {
 		return
 	}
 
-	if err = checkSchemaVersionID(versionID); err != nil {
+	res, err := c.checkCompatibility(ctx, subject, schema, versionID, false)
+	return res.IsCompatible, err
+}
+
+// checkCompatibility tests the schema against a version of the subject, or against the versions
+// the compatibility level of the subject requires when "versionID" is nil.
+func (c *Client) checkCompatibility(ctx context.Context, subject string, schema Schema, versionID interface{}, verbose bool) (res CompatibilityResult, err error) {
+	if subject == "" {
+		err = errRequired("subject")
 		return
 	}
-
-	schema := schemaOnlyJSON{
-		Schema: avroSchema,
+	if schema.Schema == "" {
+		err = errRequired("schema")
+		return
 	}
 
-	send, err := json.Marshal(schema)
+	send, err := json.Marshal(schema.schemaRequest())
 	if err != nil {
 		return
 	}
 
 	// # Test input schema against a particular version of a subject’s schema for compatibility
 	// POST /compatibility/subjects/(string: subject)/versions/(versionId: "latest" | int)
-	path := fmt.Sprintf("compatibility/"+subjectPath+"/versions/%v", subject, versionID)
-	resp, err := c.do(http.MethodPost, path, contentTypeSchemaJSON, send)
+	// # Test input schema against the subject's versions the compatibility level requires
+	// POST /compatibility/subjects/(string: subject)/versions
+	path := fmt.Sprintf("compatibility/"+subjectPath+"/versions", subject)
+	if versionID != nil {
+		path += fmt.Sprintf("/%v", versionID)
+	}
+	if verbose {
+		path += "?verbose=true"
+	}
+	resp, err := c.do(ctx, http.MethodPost, path, contentTypeSchemaJSON, send)
 	if err != nil {
 		return
 	}
 
-	var res isCompatibleJSON
 	err = c.readJSON(resp, &res)
-
-	return res.IsCompatible, err
+	return
 }
 
 // IsRegistered tells if the given "schema" is registered for this "subject".
 func (c *Client) IsRegistered(subject, schema string) (bool, Schema, error) {
+	return c.IsRegisteredContext(context.Background(), subject, schema)
+}
+
+// IsRegisteredContext same as `IsRegistered` but it accepts a context to control the request's lifetime.
+func (c *Client) IsRegisteredContext(ctx context.Context, subject, schema string) (bool, Schema, error) {
+	return c.LookupSchemaContext(ctx, subject, Schema{Schema: schema})
+}
+
+// LookupSchema tells if the given schema, of any type, is registered for this "subject".
+// The returned schema contains the subject, version and ID it is registered with.
+func (c *Client) LookupSchema(subject string, schema Schema) (bool, Schema, error) {
+	return c.LookupSchemaContext(context.Background(), subject, schema)
+}
+
+// LookupSchemaContext same as `LookupSchema` but it accepts a context to control the request's lifetime.
+func (c *Client) LookupSchemaContext(ctx context.Context, subject string, schema Schema) (bool, Schema, error) {
 	var fs Schema
 
-	sc := schemaOnlyJSON{schema}
-	send, err := json.Marshal(sc)
+	send, err := json.Marshal(schema.schemaRequest())
 	if err != nil {
 		return false, fs, err
 	}
 
 	path := fmt.Sprintf(subjectPath, subject)
-	resp, err := c.do(http.MethodPost, path, "", send)
+	resp, err := c.do(ctx, http.MethodPost, path, "", send)
 	if err != nil {
 		// schema not found?
 		if IsSchemaNotFound(err) {
@@ -223,15 +423,119 @@ func (c *Client) IsRegistered(subject, schema string) (bool, Schema, error) {
 	}
 
 	// so we have a schema.
-	return true, fs, nil
+	return true, fs.withDefaultType(), nil
 }
 
 // IsSchemaCompatible tests compatibility with a specific version of a subject's schema.
 func (c *Client) IsSchemaCompatible(subject string, avroSchema string, versionID int) (bool, error) {
-	return c.isSchemaCompatibleAtVersion(subject, avroSchema, versionID)
+	return c.IsSchemaCompatibleContext(context.Background(), subject, avroSchema, versionID)
+}
+
+// IsSchemaCompatibleContext same as `IsSchemaCompatible` but it accepts a context to control the request's lifetime.
+func (c *Client) IsSchemaCompatibleContext(ctx context.Context, subject string, avroSchema string, versionID int) (bool, error) {
+	return c.isSchemaCompatibleAtVersion(ctx, subject, Schema{Schema: avroSchema}, versionID)
 }
 
 // IsLatestSchemaCompatible tests compatibility with the latest version of a subject's schema.
 func (c *Client) IsLatestSchemaCompatible(subject string, avroSchema string) (bool, error) {
-	return c.isSchemaCompatibleAtVersion(subject, avroSchema, SchemaLatestVersion)
+	return c.IsLatestSchemaCompatibleContext(context.Background(), subject, avroSchema)
+}
+
+// IsLatestSchemaCompatibleContext same as `IsLatestSchemaCompatible` but it accepts a context to control the request's lifetime.
+func (c *Client) IsLatestSchemaCompatibleContext(ctx context.Context, subject string, avroSchema string) (bool, error) {
+	return c.isSchemaCompatibleAtVersion(ctx, subject, Schema{Schema: avroSchema}, SchemaLatestVersion)
+}
+
+// CheckCompatibility tests compatibility of a schema, of any type, with a specific version of a subject's schema.
+func (c *Client) CheckCompatibility(subject string, schema Schema, versionID int) (bool, error) {
+	return c.CheckCompatibilityContext(context.Background(), subject, schema, versionID)
+}
+
+// CheckCompatibilityContext same as `CheckCompatibility` but it accepts a context to control the request's lifetime.
+func (c *Client) CheckCompatibilityContext(ctx context.Context, subject string, schema Schema, versionID int) (bool, error) {
+	return c.isSchemaCompatibleAtVersion(ctx, subject, schema, versionID)
+}
+
+// CheckLatestCompatibility tests compatibility of a schema, of any type, with the latest version of a subject's schema.
+func (c *Client) CheckLatestCompatibility(subject string, schema Schema) (bool, error) {
+	return c.CheckLatestCompatibilityContext(context.Background(), subject, schema)
+}
+
+// CheckLatestCompatibilityContext same as `CheckLatestCompatibility` but it accepts a context to control the request's lifetime.
+func (c *Client) CheckLatestCompatibilityContext(ctx context.Context, subject string, schema Schema) (bool, error) {
+	return c.isSchemaCompatibleAtVersion(ctx, subject, schema, SchemaLatestVersion)
+}
+
+// ReferencedBy returns the IDs of the schemas which refer to the schema of the subject at the given version.
+func (c *Client) ReferencedBy(subject string, versionID int) ([]int, error) {
+	return c.ReferencedByContext(context.Background(), subject, versionID)
+}
+
+// ReferencedByContext same as `ReferencedBy` but it accepts a context to control the request's lifetime.
+func (c *Client) ReferencedByContext(ctx context.Context, subject string, versionID int) (ids []int, err error) {
+	if subject == "" {
+		err = errRequired("subject")
+		return
+	}
+
+	if err = checkSchemaVersionID(versionID); err != nil {
+		return
+	}
+
+	// # Get the IDs of the schemas that reference the specified schema
+	// GET /subjects/(string: subject)/versions/(versionId: version)/referencedby
+	path := fmt.Sprintf(subjectPath+"/versions/%d/referencedby", subject, versionID)
+	resp, respErr := c.do(ctx, http.MethodGet, path, "", nil)
+	if respErr != nil {
+		err = respErr
+		return
+	}
+
+	err = c.readJSON(resp, &ids)
+	return
+}
+
+// CompatibilityResult is the result of a verbose compatibility check.
+type CompatibilityResult struct {
+	IsCompatible bool `json:"is_compatible"`
+	// Messages are the reasons of the incompatibility, registries older than 6.1 don't send them.
+	Messages []string `json:"messages,omitempty"`
+}
+
+// CheckCompatibilityVerbose tests compatibility of a schema with a specific version of a subject's schema,
+// the result carries the reasons of the incompatibility.
+func (c *Client) CheckCompatibilityVerbose(subject string, schema Schema, versionID int) (CompatibilityResult, error) {
+	return c.CheckCompatibilityVerboseContext(context.Background(), subject, schema, versionID)
+}
+
+// CheckCompatibilityVerboseContext same as `CheckCompatibilityVerbose` but it accepts a context to control the request's lifetime.
+func (c *Client) CheckCompatibilityVerboseContext(ctx context.Context, subject string, schema Schema, versionID int) (CompatibilityResult, error) {
+	if err := checkSchemaVersionID(versionID); err != nil {
+		return CompatibilityResult{}, err
+	}
+
+	return c.checkCompatibility(ctx, subject, schema, versionID, true)
+}
+
+// CheckLatestCompatibilityVerbose tests compatibility of a schema with the latest version of a subject's schema,
+// the result carries the reasons of the incompatibility.
+func (c *Client) CheckLatestCompatibilityVerbose(subject string, schema Schema) (CompatibilityResult, error) {
+	return c.CheckLatestCompatibilityVerboseContext(context.Background(), subject, schema)
+}
+
+// CheckLatestCompatibilityVerboseContext same as `CheckLatestCompatibilityVerbose` but it accepts a context to control the request's lifetime.
+func (c *Client) CheckLatestCompatibilityVerboseContext(ctx context.Context, subject string, schema Schema) (CompatibilityResult, error) {
+	return c.checkCompatibility(ctx, subject, schema, SchemaLatestVersion, true)
+}
+
+// CheckAllVersionsCompatibility tests compatibility of a schema with the versions of the subject
+// its compatibility level requires, all of them for the transitive levels,
+// the result carries the reasons of the incompatibility.
+func (c *Client) CheckAllVersionsCompatibility(subject string, schema Schema) (CompatibilityResult, error) {
+	return c.CheckAllVersionsCompatibilityContext(context.Background(), subject, schema)
+}
+
+// CheckAllVersionsCompatibilityContext same as `CheckAllVersionsCompatibility` but it accepts a context to control the request's lifetime.
+func (c *Client) CheckAllVersionsCompatibilityContext(ctx context.Context, subject string, schema Schema) (CompatibilityResult, error) {
+	return c.checkCompatibility(ctx, subject, schema, nil, true)
 }
@@ -0,0 +1,133 @@
+package serde
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/bjornm82/schema-registry/avro"
+)
+
+// maxParsedSchemas is the maximum number of parsed schemas an `AvroCodec` caches.
+const maxParsedSchemas = 1000
+
+// AvroCodec encodes and decodes Avro values in the binary format, look the avro package
+// for the Go types of the values. The parsed schemas are cached, up to a thousand of them,
+// it's safe for concurrent use.
+type AvroCodec struct {
+	mu     sync.Mutex
+	parsed map[string]*avro.Schema
+}
+
+// NewAvroCodec returns a new Avro codec.
+func NewAvroCodec() *AvroCodec {
+	return &AvroCodec{parsed: make(map[string]*avro.Schema)}
+}
+
+func (c *AvroCodec) parse(schema Schema) (*avro.Schema, error) {
+	refs := make([]string, 0, len(schema.Referenced))
+	for _, ref := range schema.Referenced {
+		refs = append(refs, ref.Schema)
+	}
+	key := strings.Join(append(refs, schema.Schema.Schema), "\x00")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s, ok := c.parsed[key]; ok {
+		return s, nil
+	}
+	s, err := avro.Parse(schema.Schema.Schema, refs...)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.parsed) >= maxParsedSchemas {
+		// the cache is full, any entry makes room.
+		for k := range c.parsed {
+			delete(c.parsed, k)
+			break
+		}
+	}
+	c.parsed[key] = s
+	return s, nil
+}
+
+// Marshal encodes the value in the Avro binary format of the schema.
+func (c *AvroCodec) Marshal(schema Schema, v interface{}) ([]byte, error) {
+	s, err := c.parse(schema)
+	if err != nil {
+		return nil, err
+	}
+	return avro.Marshal(s, v)
+}
+
+// Unmarshal decodes a value written in the Avro binary format of the schema.
+func (c *AvroCodec) Unmarshal(schema Schema, data []byte) (interface{}, error) {
+	s, err := c.parse(schema)
+	if err != nil {
+		return nil, err
+	}
+	return avro.Unmarshal(s, data)
+}
+
+// JSONCodec encodes and decodes the values of JSON Schema schemas with encoding/json,
+// the values are not validated against the schema.
+type JSONCodec struct{}
+
+// Marshal encodes the value as JSON.
+func (JSONCodec) Marshal(_ Schema, v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes a JSON value, numbers are decoded as `json.Number`.
+func (JSONCodec) Unmarshal(_ Schema, data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// AppendMessageIndexes appends the indexes of a Protobuf message to b, a Protobuf codec writes them
+// before the message: they locate the message type in the schema, e.g. [0] is the first message
+// of the file and [1, 0] is the first message nested in the second one.
+func AppendMessageIndexes(b []byte, indexes []int) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	// the first message is the most common case, it's a single zero.
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(b, 0)
+	}
+
+	b = append(b, buf[:binary.PutVarint(buf[:], int64(len(indexes)))]...)
+	for _, i := range indexes {
+		b = append(b, buf[:binary.PutVarint(buf[:], int64(i))]...)
+	}
+	return b
+}
+
+// ReadMessageIndexes reads the indexes of a Protobuf message written by `AppendMessageIndexes`,
+// it returns the indexes and the message.
+func ReadMessageIndexes(data []byte) (indexes []int, message []byte, err error) {
+	n, size := binary.Varint(data)
+	if size <= 0 || n < 0 || n > int64(len(data)) {
+		return nil, nil, ErrInvalidPayload
+	}
+	data = data[size:]
+	if n == 0 {
+		return []int{0}, data, nil
+	}
+
+	indexes = make([]int, 0, n)
+	for ; n > 0; n-- {
+		i, size := binary.Varint(data)
+		if size <= 0 {
+			return nil, nil, ErrInvalidPayload
+		}
+		indexes = append(indexes, int(i))
+		data = data[size:]
+	}
+	return indexes, data, nil
+}
@@ -0,0 +1,149 @@
+// Package serde serializes and deserializes Kafka payloads in the Confluent wire format:
+// a zero magic byte, the 4-byte big-endian ID of the writer schema and the value encoded
+// by the codec of the schema type.
+//
+// The `Serializer` registers, or looks up, the schema of a value to get its ID and the
+// `Deserializer` fetches the writer schema by the ID of the payload. Both of them work with any
+// `schemaregistry.Registry`, a `schemaregistry.CachedClient` saves a request per message.
+package serde
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+)
+
+// magicByte is the first byte of every payload, it's the version of the wire format.
+const magicByte byte = 0
+
+// headerSize is the size of the magic byte and the schema ID.
+const headerSize = 5
+
+var (
+	// ErrInvalidPayload is returned when a payload is not in the wire format.
+	ErrInvalidPayload = errors.New("serde: invalid payload, the magic byte or the schema ID is missing")
+	// ErrNotRegistered is returned by a `Serializer` without auto registration when the schema is not registered.
+	ErrNotRegistered = errors.New("serde: the schema is not registered for the subject")
+)
+
+// Frame prefixes the encoded value with the magic byte and the schema ID.
+func Frame(id int, value []byte) []byte {
+	b := make([]byte, headerSize, headerSize+len(value))
+	b[0] = magicByte
+	binary.BigEndian.PutUint32(b[1:headerSize], uint32(id))
+	return append(b, value...)
+}
+
+// Unframe returns the schema ID and the encoded value of a payload.
+func Unframe(payload []byte) (id int, value []byte, err error) {
+	if len(payload) < headerSize || payload[0] != magicByte {
+		return 0, nil, ErrInvalidPayload
+	}
+	return int(binary.BigEndian.Uint32(payload[1:headerSize])), payload[headerSize:], nil
+}
+
+// Schema is a schema along with the schemas it refers to.
+type Schema struct {
+	schemaregistry.Schema
+	// Referenced are the schemas the schema refers to, directly or not, dependencies first.
+	Referenced []schemaregistry.Schema
+}
+
+// Codec encodes and decodes the values of a schema type.
+type Codec interface {
+	// Marshal encodes the value with the schema.
+	Marshal(schema Schema, v interface{}) ([]byte, error)
+	// Unmarshal decodes the value written with the schema.
+	Unmarshal(schema Schema, data []byte) (interface{}, error)
+}
+
+// Option describes an optional configurator that can be passed on `NewSerializer` and `NewDeserializer`.
+type Option func(*options)
+
+type options struct {
+	codecs       map[schemaregistry.SchemaType]Codec
+	autoRegister bool
+	strategy     schemaregistry.SubjectNameStrategy
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		codecs: map[schemaregistry.SchemaType]Codec{
+			schemaregistry.Avro: NewAvroCodec(),
+			schemaregistry.JSON: JSONCodec{},
+		},
+		autoRegister: true,
+		strategy:     schemaregistry.TopicNameStrategy,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o options) codec(schemaType schemaregistry.SchemaType) (Codec, error) {
+	if schemaType == "" {
+		schemaType = schemaregistry.Avro
+	}
+	c, ok := o.codecs[schemaType]
+	if !ok {
+		return nil, fmt.Errorf("serde: no codec for %s schemas, look WithCodec", schemaType)
+	}
+	return c, nil
+}
+
+// WithCodec sets the codec of a schema type. Avro and JSON Schema have a codec by default,
+// a codec for Protobuf must be provided, look `AppendMessageIndexes`.
+func WithCodec(schemaType schemaregistry.SchemaType, codec Codec) Option {
+	return func(o *options) {
+		o.codecs[schemaType] = codec
+	}
+}
+
+// WithAutoRegister sets whether a `Serializer` registers the schemas it doesn't know, it's true by default.
+// When it's false the schema must be registered already, otherwise `ErrNotRegistered` is returned.
+func WithAutoRegister(autoRegister bool) Option {
+	return func(o *options) {
+		o.autoRegister = autoRegister
+	}
+}
+
+// WithSubjectNameStrategy sets the strategy a `Serializer` uses to get the subject of a topic's schema,
+// it's `schemaregistry.TopicNameStrategy` by default.
+func WithSubjectNameStrategy(strategy schemaregistry.SubjectNameStrategy) Option {
+	return func(o *options) {
+		o.strategy = strategy
+	}
+}
+
+// resolve fetches the schemas the schema refers to, directly or not, dependencies first.
+func resolve(ctx context.Context, r schemaregistry.Registry, s schemaregistry.Schema) (Schema, error) {
+	resolved := Schema{Schema: s}
+	seen := make(map[schemaregistry.SchemaReference]bool)
+
+	var visit func(refs []schemaregistry.SchemaReference) error
+	visit = func(refs []schemaregistry.SchemaReference) error {
+		for _, ref := range refs {
+			key := schemaregistry.SchemaReference{Subject: ref.Subject, Version: ref.Version}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			referenced, err := r.GetSchemaBySubjectContext(ctx, ref.Subject, ref.Version)
+			if err != nil {
+				return err
+			}
+			if err := visit(referenced.References); err != nil {
+				return err
+			}
+			resolved.Referenced = append(resolved.Referenced, referenced)
+		}
+		return nil
+	}
+
+	return resolved, visit(s.References)
+}
@@ -0,0 +1,183 @@
+package serde
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/bjornm82/schema-registry/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+const userSchema = `{"type":"record","name":"User","fields":[{"name":"name","type":"string"},{"name":"age","type":"int","default":0}]}`
+
+func TestFrame(t *testing.T) {
+	payload := Frame(258, []byte{0xaa})
+	assert.Equal(t, []byte{0, 0, 0, 1, 2, 0xaa}, payload)
+
+	id, value, err := Unframe(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, 258, id)
+	assert.Equal(t, []byte{0xaa}, value)
+
+	_, _, err = Unframe([]byte{1, 0, 0, 0, 1})
+	assert.Equal(t, ErrInvalidPayload, err)
+	_, _, err = Unframe([]byte{0, 0})
+	assert.Equal(t, ErrInvalidPayload, err)
+}
+
+func TestSerializer_Avro(t *testing.T) {
+	r := mock.New()
+	ser := NewSerializer(r)
+	deser := NewDeserializer(r)
+
+	payload, err := ser.Serialize("users-value", schemaregistry.Schema{Schema: userSchema}, map[string]interface{}{"name": "bob"})
+	assert.NoError(t, err)
+
+	id, _, err := Unframe(payload)
+	assert.NoError(t, err)
+	s, err := r.GetLatestSchema("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, s.ID, id)
+
+	v, err := deser.Deserialize(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "bob", "age": int32(0)}, v)
+}
+
+func TestSerializer_NoAutoRegister(t *testing.T) {
+	r := mock.New()
+	ser := NewSerializer(r, WithAutoRegister(false))
+
+	_, err := ser.Serialize("users-value", schemaregistry.Schema{Schema: userSchema}, map[string]interface{}{"name": "bob"})
+	assert.Equal(t, ErrNotRegistered, err)
+
+	r.RegisterNewSchema("users-value", userSchema)
+	_, err = ser.Serialize("users-value", schemaregistry.Schema{Schema: userSchema}, map[string]interface{}{"name": "bob"})
+	assert.NoError(t, err)
+	assert.Empty(t, r.CallsOf("RegisterSchema"))
+}
+
+func TestSerializer_NoAutoRegisterCached(t *testing.T) {
+	r := mock.New()
+	r.RegisterNewSchema("users-value", userSchema)
+	ser := NewSerializer(schemaregistry.NewCachedClient(r), WithAutoRegister(false))
+
+	for i := 0; i < 3; i++ {
+		_, err := ser.Serialize("users-value", schemaregistry.Schema{Schema: userSchema}, map[string]interface{}{"name": "bob"})
+		assert.NoError(t, err)
+	}
+	assert.Len(t, r.CallsOf("LookupSchema"), 1)
+}
+
+func TestSerializer_Cached(t *testing.T) {
+	r := mock.New()
+	_, err := r.RegisterNewSchema("address-value", `{"type":"record","name":"Address","fields":[{"name":"city","type":"string"}]}`)
+	assert.NoError(t, err)
+	ser := NewSerializer(r)
+
+	for i := 0; i < 3; i++ {
+		_, err := ser.Serialize("users-value", schemaregistry.Schema{Schema: userSchema}, map[string]interface{}{"name": "bob"})
+		assert.NoError(t, err)
+	}
+	// the same schema written differently is known too.
+	_, err = ser.Serialize("users-value", schemaregistry.Schema{Schema: strings.Replace(userSchema, ",", ", ", -1)}, map[string]interface{}{"name": "bob"})
+	assert.NoError(t, err)
+	assert.Len(t, r.CallsOf("RegisterSchema"), 1)
+
+	order := schemaregistry.Schema{
+		Schema:     `{"type":"record","name":"Order","fields":[{"name":"address","type":"Address"}]}`,
+		References: []schemaregistry.SchemaReference{{Name: "Address", Subject: "address-value", Version: 1}},
+	}
+	for i := 0; i < 3; i++ {
+		_, err := ser.Serialize("orders-value", order, map[string]interface{}{"address": map[string]interface{}{"city": "Amsterdam"}})
+		assert.NoError(t, err)
+	}
+	assert.Len(t, r.CallsOf("RegisterSchema"), 2)
+	assert.Len(t, r.CallsOf("GetSchemaBySubject"), 1)
+
+	// another subject is registered too.
+	_, err = ser.Serialize("people-value", schemaregistry.Schema{Schema: userSchema}, map[string]interface{}{"name": "bob"})
+	assert.NoError(t, err)
+	assert.Len(t, r.CallsOf("RegisterSchema"), 3)
+}
+
+func TestAvroCodec_Bounded(t *testing.T) {
+	c := NewAvroCodec()
+	for i := 0; i < maxParsedSchemas+10; i++ {
+		schema := Schema{Schema: schemaregistry.Schema{Schema: fmt.Sprintf(`{"type":"fixed","name":"F","size":%d}`, i+1)}}
+		_, err := c.Marshal(schema, make([]byte, i+1))
+		assert.NoError(t, err)
+	}
+	assert.Len(t, c.parsed, maxParsedSchemas)
+}
+
+func TestSerializer_References(t *testing.T) {
+	r := mock.New()
+	_, err := r.RegisterNewSchema("address-value", `{"type":"record","name":"Address","fields":[{"name":"city","type":"string"}]}`)
+	assert.NoError(t, err)
+
+	schema := schemaregistry.Schema{
+		Schema:     `{"type":"record","name":"Order","fields":[{"name":"address","type":"Address"}]}`,
+		References: []schemaregistry.SchemaReference{{Name: "Address", Subject: "address-value", Version: 1}},
+	}
+	payload, err := NewSerializer(r).Serialize("orders-value", schema, map[string]interface{}{
+		"address": map[string]interface{}{"city": "Amsterdam"},
+	})
+	assert.NoError(t, err)
+
+	v, err := NewDeserializer(r).Deserialize(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "Amsterdam", v.(map[string]interface{})["address"].(map[string]interface{})["city"])
+}
+
+func TestSerializer_JSON(t *testing.T) {
+	r := mock.New()
+	schema := schemaregistry.Schema{Schema: `{"type":"object"}`, SchemaType: schemaregistry.JSON}
+
+	payload, err := NewSerializer(r).Serialize("users-value", schema, map[string]interface{}{"age": 3})
+	assert.NoError(t, err)
+
+	v, err := NewDeserializer(r).Deserialize(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"age": json.Number("3")}, v)
+}
+
+func TestSerializer_MissingCodec(t *testing.T) {
+	r := mock.New()
+	schema := schemaregistry.Schema{Schema: `syntax = "proto3";`, SchemaType: schemaregistry.Protobuf}
+
+	_, err := NewSerializer(r).Serialize("users-value", schema, nil)
+	assert.Error(t, err)
+	assert.Empty(t, r.Calls())
+}
+
+func TestMessageIndexes(t *testing.T) {
+	tests := [][]int{{0}, {1}, {1, 0, 2}}
+	for _, indexes := range tests {
+		b := AppendMessageIndexes(nil, indexes)
+		got, rest, err := ReadMessageIndexes(append(b, 0xaa))
+		assert.NoError(t, err)
+		assert.Equal(t, indexes, got)
+		assert.Equal(t, []byte{0xaa}, rest)
+	}
+
+	assert.Equal(t, []byte{0}, AppendMessageIndexes(nil, []int{0}))
+}
+
+func TestSerializer_SerializeTopic(t *testing.T) {
+	r := mock.New()
+	schema := schemaregistry.Schema{Schema: userSchema}
+
+	_, err := NewSerializer(r).SerializeTopic("users", true, schema, map[string]interface{}{"name": "bob"})
+	assert.NoError(t, err)
+	_, err = NewSerializer(r, WithSubjectNameStrategy(schemaregistry.TopicRecordNameStrategy)).
+		SerializeTopic("users", false, schema, map[string]interface{}{"name": "bob"})
+	assert.NoError(t, err)
+
+	subjects, err := r.Subjects()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"users-User", "users-key"}, subjects)
+}
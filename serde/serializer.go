@@ -0,0 +1,215 @@
+package serde
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/bjornm82/schema-registry/avro"
+)
+
+// maxRegisteredSchemas is the maximum number of schemas a `Serializer` caches.
+const maxRegisteredSchemas = 1000
+
+// Serializer encodes values into payloads in the wire format. The IDs and the referenced schemas of
+// the schemas are cached by subject, up to a thousand of them, so the registry is asked once per schema.
+// It's safe for concurrent use.
+type Serializer struct {
+	registry schemaregistry.Registry
+	opts     options
+
+	mu         sync.Mutex
+	registered map[registeredKey]registeredSchema
+}
+
+// registeredKey identifies a schema of a subject, the Avro schemas by their canonical form.
+type registeredKey struct {
+	subject    string
+	schemaType schemaregistry.SchemaType
+	schema     string
+	references string
+}
+
+// registeredSchema is a schema known to the registry, with its ID and referenced schemas.
+type registeredSchema struct {
+	id       int
+	resolved Schema
+}
+
+// NewSerializer returns a new `Serializer` which gets the schema IDs from the registry.
+func NewSerializer(r schemaregistry.Registry, opts ...Option) *Serializer {
+	return &Serializer{registry: r, opts: newOptions(opts), registered: make(map[registeredKey]registeredSchema)}
+}
+
+// Serialize encodes the value with the schema registered, or to be registered, under the subject.
+func (s *Serializer) Serialize(subject string, schema schemaregistry.Schema, v interface{}) ([]byte, error) {
+	return s.SerializeContext(context.Background(), subject, schema, v)
+}
+
+// SerializeContext same as `Serialize` but it accepts a context to control the lifetime of the registry requests.
+func (s *Serializer) SerializeContext(ctx context.Context, subject string, schema schemaregistry.Schema, v interface{}) ([]byte, error) {
+	codec, err := s.opts.codec(schema.SchemaType)
+	if err != nil {
+		return nil, err
+	}
+
+	registered, err := s.register(ctx, subject, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := codec.Marshal(registered.resolved, v)
+	if err != nil {
+		return nil, err
+	}
+	return Frame(registered.id, value), nil
+}
+
+// SerializeTopic encodes a key, or a value, of the topic with the schema, the subject of the schema
+// is the one of the `SubjectNameStrategy` of the serializer, look `WithSubjectNameStrategy`.
+func (s *Serializer) SerializeTopic(topic string, isKey bool, schema schemaregistry.Schema, v interface{}) ([]byte, error) {
+	return s.SerializeTopicContext(context.Background(), topic, isKey, schema, v)
+}
+
+// SerializeTopicContext same as `SerializeTopic` but it accepts a context to control the lifetime of the registry requests.
+func (s *Serializer) SerializeTopicContext(ctx context.Context, topic string, isKey bool, schema schemaregistry.Schema, v interface{}) ([]byte, error) {
+	subject, err := s.opts.strategy.SubjectName(topic, isKey, schema)
+	if err != nil {
+		return nil, err
+	}
+	return s.SerializeContext(ctx, subject, schema, v)
+}
+
+// register returns the ID and the referenced schemas of the schema, from the cache when it's known. A schema
+// written differently, with the same canonical form, is known too.
+func (s *Serializer) register(ctx context.Context, subject string, schema schemaregistry.Schema) (registeredSchema, error) {
+	key := newRegisteredKey(subject, schema)
+	if registered, ok := s.cached(key); ok {
+		return registered, nil
+	}
+	canonical := key
+	canonical.schema = canonicalSchema(schema)
+	if registered, ok := s.cached(canonical); ok {
+		s.cache(key, registered)
+		return registered, nil
+	}
+
+	id, err := s.schemaID(ctx, subject, schema)
+	if err != nil {
+		return registeredSchema{}, err
+	}
+	resolved, err := resolve(ctx, s.registry, schema)
+	if err != nil {
+		return registeredSchema{}, err
+	}
+
+	registered := registeredSchema{id: id, resolved: resolved}
+	s.cache(key, registered)
+	s.cache(canonical, registered)
+	return registered, nil
+}
+
+func (s *Serializer) cached(key registeredKey) (registeredSchema, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	registered, ok := s.registered[key]
+	return registered, ok
+}
+
+func (s *Serializer) cache(key registeredKey, registered registeredSchema) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.registered[key]; !ok && len(s.registered) >= maxRegisteredSchemas {
+		// the cache is full, any entry makes room.
+		for k := range s.registered {
+			delete(s.registered, k)
+			break
+		}
+	}
+	s.registered[key] = registered
+}
+
+func newRegisteredKey(subject string, schema schemaregistry.Schema) registeredKey {
+	key := registeredKey{subject: subject, schemaType: schema.SchemaType, schema: schema.Schema}
+	if key.schemaType == "" {
+		key.schemaType = schemaregistry.Avro
+	}
+	refs := make([]string, 0, len(schema.References))
+	for _, ref := range schema.References {
+		refs = append(refs, fmt.Sprintf("%s %s %d", ref.Name, ref.Subject, ref.Version))
+	}
+	key.references = strings.Join(refs, "\x00")
+	return key
+}
+
+// canonicalSchema returns the canonical form of an Avro schema without references, the schema as is otherwise.
+func canonicalSchema(schema schemaregistry.Schema) string {
+	if (schema.SchemaType != "" && schema.SchemaType != schemaregistry.Avro) || len(schema.References) > 0 {
+		return schema.Schema
+	}
+	parsed, err := avro.Parse(schema.Schema)
+	if err != nil {
+		return schema.Schema
+	}
+	return parsed.CanonicalForm()
+}
+
+func (s *Serializer) schemaID(ctx context.Context, subject string, schema schemaregistry.Schema) (int, error) {
+	if s.opts.autoRegister {
+		return s.registry.RegisterSchemaContext(ctx, subject, schema)
+	}
+
+	found, registered, err := s.registry.LookupSchemaContext(ctx, subject, schema)
+	if err != nil {
+		if schemaregistry.IsSubjectNotFound(err) {
+			return 0, ErrNotRegistered
+		}
+		return 0, err
+	}
+	if !found {
+		return 0, ErrNotRegistered
+	}
+	return registered.ID, nil
+}
+
+// Deserializer decodes payloads in the wire format. It's safe for concurrent use.
+type Deserializer struct {
+	registry schemaregistry.Registry
+	opts     options
+}
+
+// NewDeserializer returns a new `Deserializer` which fetches the writer schemas from the registry.
+func NewDeserializer(r schemaregistry.Registry, opts ...Option) *Deserializer {
+	return &Deserializer{registry: r, opts: newOptions(opts)}
+}
+
+// Deserialize decodes the payload with the writer schema identified by its ID.
+func (d *Deserializer) Deserialize(payload []byte) (interface{}, error) {
+	return d.DeserializeContext(context.Background(), payload)
+}
+
+// DeserializeContext same as `Deserialize` but it accepts a context to control the lifetime of the registry requests.
+func (d *Deserializer) DeserializeContext(ctx context.Context, payload []byte) (interface{}, error) {
+	id, value, err := Unframe(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := d.registry.GetSchemaDetailsByIDContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := d.opts.codec(schema.SchemaType)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := resolve(ctx, d.registry, schema)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Unmarshal(resolved, value)
+}
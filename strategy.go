@@ -0,0 +1,97 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SubjectNameStrategy tells the subject a schema of the keys or of the values of a Kafka topic is registered under.
+type SubjectNameStrategy interface {
+	SubjectName(topic string, isKey bool, schema Schema) (string, error)
+}
+
+// The strategies of the Confluent serializers.
+var (
+	// TopicNameStrategy is "<topic>-key" or "<topic>-value", one schema per topic, it's the default one.
+	TopicNameStrategy SubjectNameStrategy = topicNameStrategy{}
+	// RecordNameStrategy is the record name of the schema, many types per topic, one schema per type across topics.
+	RecordNameStrategy SubjectNameStrategy = recordNameStrategy{}
+	// TopicRecordNameStrategy is "<topic>-<record name>", many types per topic, one schema per type and topic.
+	TopicRecordNameStrategy SubjectNameStrategy = topicRecordNameStrategy{}
+)
+
+type (
+	topicNameStrategy       struct{}
+	recordNameStrategy      struct{}
+	topicRecordNameStrategy struct{}
+)
+
+func (topicNameStrategy) SubjectName(topic string, isKey bool, _ Schema) (string, error) {
+	if topic == "" {
+		return "", errRequired("topic")
+	}
+	if isKey {
+		return topic + "-key", nil
+	}
+	return topic + "-value", nil
+}
+
+func (recordNameStrategy) SubjectName(_ string, _ bool, schema Schema) (string, error) {
+	return RecordName(schema)
+}
+
+func (topicRecordNameStrategy) SubjectName(topic string, _ bool, schema Schema) (string, error) {
+	if topic == "" {
+		return "", errRequired("topic")
+	}
+	name, err := RecordName(schema)
+	if err != nil {
+		return "", err
+	}
+	return topic + "-" + name, nil
+}
+
+var (
+	protobufPackage = regexp.MustCompile(`(?m)^\s*package\s+([\w.]+)\s*;`)
+	protobufMessage = regexp.MustCompile(`(?m)^\s*message\s+(\w+)`)
+)
+
+// RecordName returns the fully qualified name of the type a schema describes: the namespace and name
+// of an Avro named type, the title of a JSON Schema and the package and first message of a Protobuf schema.
+func RecordName(schema Schema) (string, error) {
+	switch schema.withDefaultType().SchemaType {
+	case Avro:
+		var named struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		}
+		if err := json.Unmarshal([]byte(schema.Schema), &named); err != nil || named.Name == "" {
+			return "", fmt.Errorf("client: the avro schema is not a named type")
+		}
+		if named.Namespace == "" || strings.Contains(named.Name, ".") {
+			return named.Name, nil
+		}
+		return named.Namespace + "." + named.Name, nil
+	case JSON:
+		var titled struct {
+			Title string `json:"title"`
+		}
+		if err := json.Unmarshal([]byte(schema.Schema), &titled); err != nil || titled.Title == "" {
+			return "", fmt.Errorf("client: the json schema has no title")
+		}
+		return titled.Title, nil
+	case Protobuf:
+		message := protobufMessage.FindStringSubmatch(schema.Schema)
+		if message == nil {
+			return "", fmt.Errorf("client: the protobuf schema has no message")
+		}
+		if pkg := protobufPackage.FindStringSubmatch(schema.Schema); pkg != nil {
+			return pkg[1] + "." + message[1], nil
+		}
+		return message[1], nil
+	default:
+		return "", fmt.Errorf("client: unknown schema type %s", schema.SchemaType)
+	}
+}
@@ -0,0 +1,53 @@
+package schemaregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubjectNameStrategies(t *testing.T) {
+	user := Schema{Schema: `{"type":"record","name":"User","namespace":"com.example","fields":[]}`}
+
+	tests := []struct {
+		strategy SubjectNameStrategy
+		isKey    bool
+		subject  string
+	}{
+		{TopicNameStrategy, false, "users-value"},
+		{TopicNameStrategy, true, "users-key"},
+		{RecordNameStrategy, false, "com.example.User"},
+		{TopicRecordNameStrategy, true, "users-com.example.User"},
+	}
+
+	for _, tt := range tests {
+		subject, err := tt.strategy.SubjectName("users", tt.isKey, user)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.subject, subject)
+	}
+
+	_, err := TopicNameStrategy.SubjectName("", false, user)
+	assert.Error(t, err)
+	_, err = RecordNameStrategy.SubjectName("users", false, Schema{Schema: `"string"`})
+	assert.Error(t, err)
+}
+
+func TestRecordName(t *testing.T) {
+	tests := []struct {
+		schema Schema
+		name   string
+	}{
+		{Schema{Schema: `{"type":"record","name":"User","fields":[]}`}, "User"},
+		{Schema{Schema: `{"type":"record","name":"com.example.User","namespace":"org.other","fields":[]}`}, "com.example.User"},
+		{Schema{Schema: `{"type":"enum","name":"Role","namespace":"com.example","symbols":["A"]}`, SchemaType: Avro}, "com.example.Role"},
+		{Schema{Schema: `{"title":"com.example.User","type":"object"}`, SchemaType: JSON}, "com.example.User"},
+		{Schema{Schema: "syntax = \"proto3\";\npackage com.example;\n\nmessage User {\n  string name = 1;\n}\n", SchemaType: Protobuf}, "com.example.User"},
+		{Schema{Schema: "syntax = \"proto3\";\nmessage User {}\n", SchemaType: Protobuf}, "User"},
+	}
+
+	for _, tt := range tests {
+		name, err := RecordName(tt.schema)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.name, name)
+	}
+}
@@ -1,22 +1,43 @@
 package schemaregistry
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 )
 
 const (
-	subjectsPath = "subjects"
-	subjectPath  = subjectsPath + "/%s"
-	schemaPath   = "schemas/ids/%d"
+	subjectsPath    = "subjects"
+	subjectPath     = subjectsPath + "/%s"
+	schemaPath      = "schemas/ids/%d"
+	schemaTypesPath = "schemas/types"
 )
 
 // Subjects returns a list of the available subjects(schemas).
 // https://docs.confluent.io/current/schema-registry/docs/api.html#subjects
 func (c *Client) Subjects() (subjects []string, err error) {
+	return c.SubjectsContext(context.Background())
+}
+
+// SubjectsContext same as `Subjects` but it accepts a context to control the request's lifetime.
+func (c *Client) SubjectsContext(ctx context.Context) (subjects []string, err error) {
+	return c.subjects(ctx, false)
+}
+
+// SubjectsIncludingDeleted returns a list of the available subjects, including the soft-deleted ones.
+func (c *Client) SubjectsIncludingDeleted() (subjects []string, err error) {
+	return c.SubjectsIncludingDeletedContext(context.Background())
+}
+
+// SubjectsIncludingDeletedContext same as `SubjectsIncludingDeleted` but it accepts a context to control the request's lifetime.
+func (c *Client) SubjectsIncludingDeletedContext(ctx context.Context) (subjects []string, err error) {
+	return c.subjects(ctx, true)
+}
+
+func (c *Client) subjects(ctx context.Context, deleted bool) (subjects []string, err error) {
 	// # List all available subjects
-	// GET /subjects
-	resp, respErr := c.do(http.MethodGet, subjectsPath, "", nil)
+	// GET /subjects?deleted=(boolean)
+	resp, respErr := c.do(ctx, http.MethodGet, withDeleted(subjectsPath, deleted), "", nil)
 	if respErr != nil {
 		err = respErr
 		return
@@ -28,15 +49,35 @@ func (c *Client) Subjects() (subjects []string, err error) {
 
 // Versions returns all schema version numbers registered for this subject.
 func (c *Client) Versions(subject string) (versions []int, err error) {
+	return c.VersionsContext(context.Background(), subject)
+}
+
+// VersionsContext same as `Versions` but it accepts a context to control the request's lifetime.
+func (c *Client) VersionsContext(ctx context.Context, subject string) (versions []int, err error) {
+	return c.versions(ctx, subject, false)
+}
+
+// VersionsIncludingDeleted returns all schema version numbers registered for this subject,
+// including the soft-deleted ones.
+func (c *Client) VersionsIncludingDeleted(subject string) (versions []int, err error) {
+	return c.VersionsIncludingDeletedContext(context.Background(), subject)
+}
+
+// VersionsIncludingDeletedContext same as `VersionsIncludingDeleted` but it accepts a context to control the request's lifetime.
+func (c *Client) VersionsIncludingDeletedContext(ctx context.Context, subject string) (versions []int, err error) {
+	return c.versions(ctx, subject, true)
+}
+
+func (c *Client) versions(ctx context.Context, subject string, deleted bool) (versions []int, err error) {
 	if subject == "" {
 		err = errRequired("subject")
 		return
 	}
 
 	// # List all versions of a particular subject
-	// GET /subjects/(string: subject)/versions
+	// GET /subjects/(string: subject)/versions?deleted=(boolean)
 	path := fmt.Sprintf(subjectPath, subject+"/versions")
-	resp, respErr := c.do(http.MethodGet, path, "", nil)
+	resp, respErr := c.do(ctx, http.MethodGet, withDeleted(path, deleted), "", nil)
 	if respErr != nil {
 		err = respErr
 		return
@@ -50,14 +91,35 @@ func (c *Client) Versions(subject string) (versions []int, err error) {
 // It is recommended to use this API only when a topic needs to be recycled or in development environment.
 // Returns the versions of the schema deleted under this subject.
 func (c *Client) DeleteSubject(subject string) (versions []int, err error) {
+	return c.DeleteSubjectContext(context.Background(), subject)
+}
+
+// DeleteSubjectContext same as `DeleteSubject` but it accepts a context to control the request's lifetime.
+func (c *Client) DeleteSubjectContext(ctx context.Context, subject string) (versions []int, err error) {
+	return c.deleteSubject(ctx, subject, false)
+}
+
+// PermanentlyDeleteSubject deletes the specified subject along with its schemas for good,
+// the subject must be soft-deleted with `DeleteSubject` first.
+// Returns the versions of the schema deleted under this subject.
+func (c *Client) PermanentlyDeleteSubject(subject string) (versions []int, err error) {
+	return c.PermanentlyDeleteSubjectContext(context.Background(), subject)
+}
+
+// PermanentlyDeleteSubjectContext same as `PermanentlyDeleteSubject` but it accepts a context to control the request's lifetime.
+func (c *Client) PermanentlyDeleteSubjectContext(ctx context.Context, subject string) (versions []int, err error) {
+	return c.deleteSubject(ctx, subject, true)
+}
+
+func (c *Client) deleteSubject(ctx context.Context, subject string, permanent bool) (versions []int, err error) {
 	if subject == "" {
 		err = errRequired("subject")
 		return
 	}
 
-	// DELETE /subjects/(string: subject)
+	// DELETE /subjects/(string: subject)?permanent=(boolean)
 	path := fmt.Sprintf(subjectPath, subject)
-	resp, respErr := c.do(http.MethodDelete, path, "", nil)
+	resp, respErr := c.do(ctx, http.MethodDelete, withPermanent(path, permanent), "", nil)
 	if respErr != nil {
 		err = respErr
 		return
@@ -66,3 +128,63 @@ func (c *Client) DeleteSubject(subject string) (versions []int, err error) {
 	err = c.readJSON(resp, &versions)
 	return
 }
+
+// DeleteSchemaVersion deletes a specific version of the schema registered under the subject,
+// the version can still be looked up with `VersionsIncludingDeleted` until it's permanently deleted.
+// Returns the deleted version.
+func (c *Client) DeleteSchemaVersion(subject string, versionID int) (version int, err error) {
+	return c.DeleteSchemaVersionContext(context.Background(), subject, versionID)
+}
+
+// DeleteSchemaVersionContext same as `DeleteSchemaVersion` but it accepts a context to control the request's lifetime.
+func (c *Client) DeleteSchemaVersionContext(ctx context.Context, subject string, versionID int) (version int, err error) {
+	return c.deleteSchemaVersion(ctx, subject, versionID, false)
+}
+
+// PermanentlyDeleteSchemaVersion deletes a specific version of the schema registered under the subject for good,
+// the version must be soft-deleted with `DeleteSchemaVersion` first.
+// Returns the deleted version.
+func (c *Client) PermanentlyDeleteSchemaVersion(subject string, versionID int) (version int, err error) {
+	return c.PermanentlyDeleteSchemaVersionContext(context.Background(), subject, versionID)
+}
+
+// PermanentlyDeleteSchemaVersionContext same as `PermanentlyDeleteSchemaVersion` but it accepts a context to control the request's lifetime.
+func (c *Client) PermanentlyDeleteSchemaVersionContext(ctx context.Context, subject string, versionID int) (version int, err error) {
+	return c.deleteSchemaVersion(ctx, subject, versionID, true)
+}
+
+func (c *Client) deleteSchemaVersion(ctx context.Context, subject string, versionID int, permanent bool) (version int, err error) {
+	if subject == "" {
+		err = errRequired("subject")
+		return
+	}
+
+	if err = checkSchemaVersionID(versionID); err != nil {
+		return
+	}
+
+	// DELETE /subjects/(string: subject)/versions/(versionId: version)?permanent=(boolean)
+	path := fmt.Sprintf(subjectPath+"/versions/%d", subject, versionID)
+	resp, respErr := c.do(ctx, http.MethodDelete, withPermanent(path, permanent), "", nil)
+	if respErr != nil {
+		err = respErr
+		return
+	}
+
+	err = c.readJSON(resp, &version)
+	return
+}
+
+func withDeleted(path string, deleted bool) string {
+	if deleted {
+		return path + "?deleted=true"
+	}
+	return path
+}
+
+func withPermanent(path string, permanent bool) string {
+	if permanent {
+		return path + "?permanent=true"
+	}
+	return path
+}
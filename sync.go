@@ -0,0 +1,324 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Syncer copies the subjects of a source registry to a target registry, e.g. to migrate to another
+// registry or to keep a mirror up to date. It's not safe for concurrent use.
+type Syncer struct {
+	source, target Registry
+	filter         func(subject string) bool
+	rename         func(subject string) string
+	preserveIDs    bool
+
+	// synced are the source versions known to be in the target, they are not checked again.
+	synced map[subjectVersionKey]bool
+}
+
+// SyncOption describes an optional configurator that can be passed on `NewSyncer`.
+type SyncOption func(*Syncer)
+
+// WithSubjectFilter selects the subjects to copy, all of them are copied by default.
+func WithSubjectFilter(filter func(subject string) bool) SyncOption {
+	return func(s *Syncer) {
+		s.filter = filter
+	}
+}
+
+// WithSubjectRename sets the name of the target subject of a source subject, the names are kept by default.
+// The subjects of the references are renamed too.
+func WithSubjectRename(rename func(subject string) string) SyncOption {
+	return func(s *Syncer) {
+		s.rename = rename
+	}
+}
+
+// WithPreservedIDs sets whether the schemas keep their IDs and versions in the target, it's true by default.
+// The target subjects are switched to `Import` mode while they are copied and then back to their previous
+// mode, when the target refuses it, or when an ID or a version is taken in the target already, the schemas
+// are registered with the IDs and versions the target gives them.
+func WithPreservedIDs(preserve bool) SyncOption {
+	return func(s *Syncer) {
+		s.preserveIDs = preserve
+	}
+}
+
+// NewSyncer returns a new `Syncer` from the source to the target registry.
+func NewSyncer(source, target Registry, opts ...SyncOption) *Syncer {
+	s := &Syncer{
+		source:      source,
+		target:      target,
+		filter:      func(string) bool { return true },
+		rename:      func(subject string) string { return subject },
+		preserveIDs: true,
+		synced:      make(map[subjectVersionKey]bool),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SyncedSchema is a version of a source subject copied to the target.
+type SyncedSchema struct {
+	Subject       string `json:"subject"`
+	Version       int    `json:"version"`
+	ID            int    `json:"id"`
+	TargetSubject string `json:"targetSubject"`
+	TargetID      int    `json:"targetId"`
+}
+
+// Sync copies the versions of the selected subjects the target doesn't have, in the order of their IDs,
+// so the referenced schemas are copied before the schemas referring to them. The soft-deleted versions are
+// not copied. A version copied, or found in the target, by a previous call is not checked again.
+func (s *Syncer) Sync() ([]SyncedSchema, error) {
+	return s.SyncContext(context.Background())
+}
+
+// SyncContext same as `Sync` but it accepts a context to control the lifetime of the requests.
+// The modes of the target subjects are restored even when the context is done.
+func (s *Syncer) SyncContext(ctx context.Context) (copied []SyncedSchema, err error) {
+	pending, err := s.pending(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	// modes are the target subjects tried in import mode, nil when the IDs are not preserved.
+	var modes map[string]importedMode
+	if s.preserveIDs {
+		modes = make(map[string]importedMode)
+		defer func() {
+			if restoreErr := s.restoreModes(modes); restoreErr != nil {
+				if err != nil {
+					restoreErr = fmt.Errorf("%v, %v", err, restoreErr)
+				}
+				err = restoreErr
+			}
+		}()
+	}
+
+	for _, schema := range pending {
+		synced, err := s.copy(ctx, schema, modes)
+		if err != nil {
+			if ctx.Err() != nil {
+				return copied, ctx.Err()
+			}
+			return copied, fmt.Errorf("subject %s version %d: %v", schema.Subject, schema.Version, err)
+		}
+		s.synced[subjectVersionKey{schema.Subject, schema.Version}] = true
+		if synced != nil {
+			copied = append(copied, *synced)
+		}
+	}
+	return copied, nil
+}
+
+// pending returns the source versions of the selected subjects which were not synced yet, by ID.
+func (s *Syncer) pending(ctx context.Context) ([]Schema, error) {
+	subjects, err := s.source.SubjectsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Schema
+	for _, subject := range subjects {
+		if !s.filter(subject) {
+			continue
+		}
+		versions, err := s.source.VersionsContext(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			if s.synced[subjectVersionKey{subject, version}] {
+				continue
+			}
+			schema, err := s.source.GetSchemaBySubjectContext(ctx, subject, version)
+			if err != nil {
+				return nil, err
+			}
+			schema.Subject, schema.Version = subject, version
+			pending = append(pending, schema.withDefaultType())
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].ID != pending[j].ID {
+			return pending[i].ID < pending[j].ID
+		}
+		return pending[i].Subject < pending[j].Subject
+	})
+	return pending, nil
+}
+
+// copy copies a source version to the target, it returns nil when the target has it already.
+func (s *Syncer) copy(ctx context.Context, schema Schema, modes map[string]importedMode) (*SyncedSchema, error) {
+	target := s.targetSchema(schema)
+	targetSubject := s.rename(schema.Subject)
+
+	found, _, err := s.target.LookupSchemaContext(ctx, targetSubject, target)
+	if err != nil && !IsSubjectNotFound(err) {
+		return nil, err
+	}
+	if found {
+		return nil, nil
+	}
+
+	var id int
+	if modes != nil && s.importMode(ctx, targetSubject, modes) {
+		imported := target
+		imported.ID, imported.Version = schema.ID, schema.Version
+		id, err = s.target.ImportSchemaContext(ctx, targetSubject, imported)
+		if isImportConflict(err) {
+			// the ID or the version is taken by another schema, the target gives new ones.
+			id, err = s.target.RegisterSchemaContext(ctx, targetSubject, target)
+		}
+	} else {
+		id, err = s.target.RegisterSchemaContext(ctx, targetSubject, target)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyncedSchema{
+		Subject:       schema.Subject,
+		Version:       schema.Version,
+		ID:            schema.ID,
+		TargetSubject: targetSubject,
+		TargetID:      id,
+	}, nil
+}
+
+// targetSchema returns the schema to register in the target, its references point to the renamed subjects.
+func (s *Syncer) targetSchema(schema Schema) Schema {
+	target := Schema{Schema: schema.Schema, SchemaType: schema.SchemaType}
+	for _, ref := range schema.References {
+		ref.Subject = s.rename(ref.Subject)
+		target.References = append(target.References, ref)
+	}
+	return target
+}
+
+// importedMode is a target subject tried in import mode.
+type importedMode struct {
+	// importing tells whether the subject is in import mode, false when the target refused it.
+	importing bool
+	// switched tells whether the subject was switched to import mode, so it's switched back.
+	switched bool
+	// previous is the mode of the subject before it was switched, empty when it had none of its own.
+	previous Mode
+}
+
+// importMode switches the target subject to import mode, once, it reports whether the subject is in import mode.
+func (s *Syncer) importMode(ctx context.Context, subject string, modes map[string]importedMode) bool {
+	if mode, tried := modes[subject]; tried {
+		return mode.importing
+	}
+
+	var mode importedMode
+	effective, err := s.target.GetEffectiveModeContext(ctx, subject)
+	if err == nil && effective == Import {
+		mode.importing = true
+	} else if err == nil {
+		if mode.previous, err = s.target.GetModeContext(ctx, subject); err == nil {
+			// the subject may have versions already, e.g. when a new version is synced.
+			_, err = s.target.SetModeForcedContext(ctx, Import, subject)
+		}
+		mode.importing, mode.switched = err == nil, err == nil
+	}
+	modes[subject] = mode
+	return mode.importing
+}
+
+// isImportConflict reports whether the target refused an import, e.g. because the ID or the version is taken.
+func isImportConflict(err error) bool {
+	resErr, ok := err.(ResourceError)
+	return ok && resErr.ErrorCode == operationNotPermittedCode
+}
+
+// restoreModes puts back the modes the subjects had before they were switched to import mode, a subject
+// which had no mode of its own has its mode deleted. It doesn't use the context of the sync, which may be done.
+func (s *Syncer) restoreModes(modes map[string]importedMode) error {
+	ctx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+	defer cancel()
+
+	var failed []string
+	for subject, mode := range modes {
+		if !mode.switched {
+			continue
+		}
+		var err error
+		if mode.previous == "" {
+			_, err = s.target.DeleteModeContext(ctx, subject)
+		} else {
+			_, err = s.target.SetModeForcedContext(ctx, mode.previous, subject)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", subject, err))
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("the target subjects are still in %s mode, %s", Import, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Verify checks that every version of the selected source subjects is in the target, with the same ID
+// when the IDs are preserved. It returns the problems found, none when the target is in sync.
+func (s *Syncer) Verify() ([]string, error) {
+	return s.VerifyContext(context.Background())
+}
+
+// VerifyContext same as `Verify` but it accepts a context to control the lifetime of the requests.
+func (s *Syncer) VerifyContext(ctx context.Context) ([]string, error) {
+	synced := s.synced
+	// every version is checked, not only the ones which were not synced.
+	s.synced = make(map[subjectVersionKey]bool)
+	schemas, err := s.pending(ctx)
+	s.synced = synced
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	for _, schema := range schemas {
+		targetSubject := s.rename(schema.Subject)
+		found, registered, err := s.target.LookupSchemaContext(ctx, targetSubject, s.targetSchema(schema))
+		if err != nil && !IsSubjectNotFound(err) {
+			return nil, err
+		}
+		switch {
+		case !found:
+			problems = append(problems, fmt.Sprintf("%s version %d is missing from %s", schema.Subject, schema.Version, targetSubject))
+		case s.preserveIDs && registered.ID != schema.ID:
+			problems = append(problems, fmt.Sprintf("%s version %d has id %d in the source and %d in %s",
+				schema.Subject, schema.Version, schema.ID, registered.ID, targetSubject))
+		}
+	}
+	return problems, nil
+}
+
+// Watch syncs every interval until the context is done, it calls onSync with the result of every sync,
+// including the first one which runs right away. It returns the error of the context.
+func (s *Syncer) Watch(ctx context.Context, interval time.Duration, onSync func([]SyncedSchema, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		onSync(s.SyncContext(ctx))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
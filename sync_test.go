@@ -0,0 +1,128 @@
+package schemaregistry_test
+
+import (
+	"context"
+	"testing"
+
+	schemaregistry "github.com/bjornm82/schema-registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncer(t *testing.T) {
+	_, src := newTestRegistry(t)
+	src.RegisterNewSchema("users-value", userV1)
+	src.RegisterNewSchema("address-value", `{"type":"record","name":"Address","fields":[{"name":"city","type":"string"}]}`)
+	src.RegisterSchema("orders-value", schemaregistry.Schema{
+		Schema:     `{"type":"record","name":"Order","fields":[{"name":"address","type":"Address"}]}`,
+		References: []schemaregistry.SchemaReference{{Name: "Address", Subject: "address-value", Version: 1}},
+	})
+	src.RegisterNewSchema("internal-value", `"string"`)
+
+	_, dst := newTestRegistry(t)
+	syncer := schemaregistry.NewSyncer(src, dst,
+		schemaregistry.WithSubjectFilter(func(subject string) bool { return subject != "internal-value" }),
+		schemaregistry.WithSubjectRename(func(subject string) string { return "prod." + subject }),
+	)
+
+	copied, err := syncer.Sync()
+	assert.NoError(t, err)
+	if assert.Len(t, copied, 3) {
+		// in the order of the IDs, the referenced schema first.
+		assert.Equal(t, "prod.address-value", copied[1].TargetSubject)
+		assert.Equal(t, "prod.orders-value", copied[2].TargetSubject)
+		for _, s := range copied {
+			assert.Equal(t, s.ID, s.TargetID)
+		}
+	}
+
+	orders, err := dst.GetLatestSchema("prod.orders-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod.address-value", orders.References[0].Subject)
+	_, err = dst.GetLatestSchema("prod.internal-value")
+	assert.True(t, schemaregistry.IsSubjectNotFound(err))
+
+	// the target subjects get their previous mode back, the global one.
+	mode, err := dst.GetMode("prod.users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Mode(""), mode)
+
+	problems, err := syncer.Verify()
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+
+	// only the new version is copied, the target subject keeps its own mode.
+	dst.SetMode(schemaregistry.ReadOnly, "prod.users-value")
+	id, _ := src.RegisterNewSchema("users-value", userV2)
+	copied, err = syncer.Sync()
+	assert.NoError(t, err)
+	assert.Equal(t, []schemaregistry.SyncedSchema{{Subject: "users-value", Version: 2, ID: id, TargetSubject: "prod.users-value", TargetID: id}}, copied)
+	mode, err = dst.GetMode("prod.users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.ReadOnly, mode)
+	dst.SetMode(schemaregistry.ReadWrite, "prod.users-value") // so the version can be deleted.
+
+	// a version deleted from the target is reported.
+	dst.DeleteSchemaVersion("prod.users-value", 2)
+	problems, err = syncer.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"users-value version 2 is missing from prod.users-value"}, problems)
+}
+
+func TestSyncer_IDTaken(t *testing.T) {
+	_, src := newTestRegistry(t)
+	id, _ := src.RegisterNewSchema("users-value", userV1)
+	_, dst := newTestRegistry(t)
+	// the ID of the source schema is taken in the target.
+	taken, _ := dst.RegisterNewSchema("other-value", `"string"`)
+	assert.Equal(t, id, taken)
+	// a mode of its own is kept even when it's the global one.
+	dst.SetMode(schemaregistry.ReadWrite, "users-value")
+
+	syncer := schemaregistry.NewSyncer(src, dst, schemaregistry.WithSubjectFilter(func(subject string) bool { return subject == "users-value" }))
+	copied, err := syncer.Sync()
+	assert.NoError(t, err)
+	if assert.Len(t, copied, 1) {
+		registered, err := dst.GetLatestSchema("users-value")
+		assert.NoError(t, err)
+		assert.NotEqual(t, id, copied[0].TargetID)
+		assert.Equal(t, registered.ID, copied[0].TargetID)
+	}
+
+	mode, err := dst.GetMode("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.ReadWrite, mode)
+
+	problems, err := syncer.Verify()
+	assert.NoError(t, err)
+	assert.Len(t, problems, 1)
+}
+
+// cancelingRegistry cancels the context of the sync when a schema is imported.
+type cancelingRegistry struct {
+	schemaregistry.Registry
+	cancel context.CancelFunc
+}
+
+func (r cancelingRegistry) ImportSchemaContext(ctx context.Context, subject string, schema schemaregistry.Schema) (int, error) {
+	r.cancel()
+	return r.Registry.ImportSchemaContext(ctx, subject, schema)
+}
+
+func TestSyncer_Canceled(t *testing.T) {
+	_, src := newTestRegistry(t)
+	src.RegisterNewSchema("users-value", userV1)
+	_, dst := newTestRegistry(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	syncer := schemaregistry.NewSyncer(src, cancelingRegistry{dst, cancel})
+
+	copied, err := syncer.SyncContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, copied)
+
+	// the import mode of the target subject is dropped anyway.
+	mode, err := dst.GetMode("users-value")
+	assert.NoError(t, err)
+	assert.Equal(t, schemaregistry.Mode(""), mode)
+}
@@ -0,0 +1,135 @@
+package schemaregistry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+const errTLSTransport = "tls options require the http client to use an *http.Transport"
+
+// tlsConfig returns the TLS configuration of the client, it's created on first use.
+func (c *Client) tlsConfig() *tls.Config {
+	if c.tls == nil {
+		c.tls = &tls.Config{}
+	}
+
+	return c.tls
+}
+
+// WithCACert adds the PEM encoded certificates to the pool of the trusted certificate authorities.
+// When no CA is set, the host's root CA set is used.
+func WithCACert(pemCerts []byte) Option {
+	return func(c *Client) {
+		cfg := c.tlsConfig()
+		if cfg.RootCAs == nil {
+			cfg.RootCAs = x509.NewCertPool()
+		}
+		if !cfg.RootCAs.AppendCertsFromPEM(pemCerts) {
+			c.optionErr(errors.New("client: no valid PEM certificate found in the CA bundle"))
+		}
+	}
+}
+
+// WithCACertFile same as `WithCACert` but it reads the CA bundle from a file.
+func WithCACertFile(filename string) Option {
+	return func(c *Client) {
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			c.optionErr(fmt.Errorf("client: unable to read CA bundle: %v", err))
+			return
+		}
+		WithCACert(b)(c)
+	}
+}
+
+// WithClientCert sets the PEM encoded certificate and private key
+// the client presents to the registry for mutual TLS authentication.
+func WithClientCert(certPEM, keyPEM []byte) Option {
+	return func(c *Client) {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			c.optionErr(fmt.Errorf("client: invalid client certificate: %v", err))
+			return
+		}
+		cfg := c.tlsConfig()
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+}
+
+// WithClientCertFile same as `WithClientCert` but it reads the certificate and the private key from files.
+func WithClientCertFile(certFile, keyFile string) Option {
+	return func(c *Client) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			c.optionErr(fmt.Errorf("client: unable to load client certificate: %v", err))
+			return
+		}
+		cfg := c.tlsConfig()
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+}
+
+// WithServerName sets the name used to verify the registry's certificate,
+// useful when the registry is reached through an address its certificate is not issued for.
+func WithServerName(serverName string) Option {
+	return func(c *Client) {
+		c.tlsConfig().ServerName = serverName
+	}
+}
+
+// WithMinTLSVersion sets the minimum TLS version accepted, e.g. `tls.VersionTLS12`.
+func WithMinTLSVersion(version uint16) Option {
+	return func(c *Client) {
+		c.tlsConfig().MinVersion = version
+	}
+}
+
+// applyTLS installs the TLS configuration, if any, on a copy of the http client and of its transport,
+// the client passed through `UsingClient` may be shared so it's left as is. The TLS configuration
+// of its transport is kept, the options override it.
+func (c *Client) applyTLS() error {
+	if c.tls == nil {
+		return nil
+	}
+
+	httpClient, ok := c.client.(*http.Client)
+	if !ok {
+		return errors.New(errTLSTransport)
+	}
+
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		return errors.New(errTLSTransport)
+	}
+
+	cfg := c.tls
+	if base := transport.TLSClientConfig; base != nil {
+		cfg = base.Clone()
+		mergeTLS(cfg, c.tls)
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = cfg
+	copied := *httpClient
+	copied.Transport = transport
+	c.client = &copied
+	return nil
+}
+
+// mergeTLS sets the settings of the options on the TLS configuration of a transport.
+func mergeTLS(cfg, options *tls.Config) {
+	if options.RootCAs != nil {
+		cfg.RootCAs = options.RootCAs
+	}
+	cfg.Certificates = append(cfg.Certificates, options.Certificates...)
+	if options.ServerName != "" {
+		cfg.ServerName = options.ServerName
+	}
+	if options.MinVersion != 0 {
+		cfg.MinVersion = options.MinVersion
+	}
+}
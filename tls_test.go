@@ -0,0 +1,171 @@
+package schemaregistry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert creates a certificate signed by "parent", or a self-signed CA when parent is nil.
+func newTestCert(t *testing.T, parent *testCert, cn string) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{cn},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCert(t, nil, "test-ca")
+	serverCert := newTestCert(t, ca, "registry.internal")
+	clientCert := newTestCert(t, ca, "client")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	serverKeyPair, err := tls.X509KeyPair(serverCert.certPEM, serverCert.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Len(t, r.TLS.PeerCertificates, 1)
+		w.Header().Set(contentTypeHeaderKey, contentTypeJSON)
+		w.Write([]byte(`["subject"]`))
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverKeyPair},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	host, portStr, _ := net.SplitHostPort(u.Host)
+	port, _ := strconv.Atoi(portStr)
+
+	c, err := NewClient(host, port, true,
+		WithCACert(ca.certPEM),
+		WithClientCert(clientCert.certPEM, clientCert.keyPEM),
+		WithServerName("registry.internal"),
+		WithMinTLSVersion(tls.VersionTLS12),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subs, err := c.Subjects()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"subject"}, subs)
+
+	// without the client certificate the handshake fails.
+	c, err = NewClient(host, port, true, WithCACert(ca.certPEM), WithServerName("registry.internal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Subjects()
+	assert.Error(t, err)
+}
+
+func TestTLSOptions_CustomClient(t *testing.T) {
+	transport := &http.Transport{TLSClientConfig: &tls.Config{ServerName: "registry.internal", MinVersion: tls.VersionTLS12}}
+	httpClient := &http.Client{Transport: transport}
+
+	c, err := NewClient("localhost", 8081, true, UsingClient(httpClient), WithMinTLSVersion(tls.VersionTLS13))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the TLS configuration of the transport is kept, the options override it.
+	cfg := c.client.(*http.Client).Transport.(*http.Transport).TLSClientConfig
+	assert.Equal(t, "registry.internal", cfg.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+
+	// the caller's client and transport are left as is.
+	assert.True(t, httpClient.Transport == transport)
+	assert.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion)
+	assert.False(t, c.client == httpClient)
+}
+
+func TestTLSOptions_Failed(t *testing.T) {
+	_, err := NewClient("localhost", 8081, true, WithCACert([]byte("not a certificate")))
+	assert.Error(t, err)
+
+	_, err = NewClient("localhost", 8081, true, WithCACertFile("testdata/does-not-exist.pem"))
+	assert.Error(t, err)
+
+	_, err = NewClient("localhost", 8081, true, WithClientCert([]byte("cert"), []byte("key")))
+	assert.Error(t, err)
+
+	_, err = NewClient("localhost", 8081, true, WithClientCertFile("cert.pem", "key.pem"))
+	assert.Error(t, err)
+
+	httpClient := &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}
+	_, err = NewClient("localhost", 8081, true, UsingClient(httpClient), WithServerName("registry"))
+	assert.EqualError(t, err, errTLSTransport)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}